@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"notion2md/pkg/config"
+)
+
+// 退出码，供 CI 判断运行结果
+const (
+	exitOK      = 0
+	exitFailure = 1 // 部分来源或文章失败，或者命令执行失败
+	exitConfig  = 2 // 配置或命令行参数有误
+)
+
+// command 是一个子命令
+type command struct {
+	Name    string
+	Summary string
+	// DryRun 为 true 时命令支持 -dry-run
+	DryRun bool
+	Run    func(ctx context.Context, args []string) error
+}
+
+// commands 按帮助信息中的顺序列出所有子命令，没有指定命令时执行 sync
+var commands = []command{
+	{Name: "sync", Summary: "同步每个来源中待发布和待删除的文章（默认命令）", DryRun: true, Run: runSync},
+	{Name: "page", Summary: "转换单篇文章，不论它处于什么状态", DryRun: true, Run: runPage},
+	{Name: "watch", Summary: "定期轮询数据库，同步有变化的文章", Run: runWatch},
+	{Name: "serve", Summary: "启动 HTTP 服务，收到 webhook 时同步", Run: runServe},
+	{Name: "init", Summary: "读取数据库结构，生成配置文件", Run: runInit},
+	{Name: "doctor", Summary: "检查令牌、数据库结构、模板和存储", Run: runDoctorCommand},
+	{Name: "gc", Summary: "删除本地存储中没有被任何文章引用的媒体文件", DryRun: true, Run: runGC},
+	{Name: "export", Summary: "导出待发布和已发布的文章，不更新 Notion 中的状态", Run: runExport},
+}
+
+// 全局参数，可以写在子命令之前或之后
+var (
+	configFile string
+	envFile    string
+	logFormat  string
+	logLevel   string
+	quiet      bool
+	noColor    bool
+	strict     bool
+	dryRun     bool
+	recordDir  string
+	replayDir  string
+
+	// configFlags 收集命令行中设置的配置字段，例如 -storage.s3.bucket
+	configFlags = &config.Flags{}
+)
+
+// globalFlags 和 fieldFlags 分别为全局参数和配置字段的参数，每个子命令的参数中都会加入这些参数
+var (
+	globalFlags = flag.NewFlagSet("notion2md", flag.ContinueOnError)
+	fieldFlags  = flag.NewFlagSet("notion2md", flag.ContinueOnError)
+)
+
+func init() {
+	globalFlags.StringVar(&configFile, "config", "", "配置文件路径（.json、.yaml、.yml 或 .toml，也可以是带有 params.notion2md 的 Hugo 站点配置），默认为 NOTION2MD_CONFIG 或工作目录下的 notion.config.json、notion2md.yaml、notion2md.yml 或 notion2md.toml，都没有时只使用环境变量和参数")
+	globalFlags.StringVar(&envFile, "env", "", "环境变量文件路径，默认为工作目录下的 .env（不存在时忽略），已经存在的环境变量不会被覆盖")
+	globalFlags.StringVar(&logFormat, "log-format", "text", "日志格式，`text` 或 json")
+	globalFlags.StringVar(&logLevel, "log-level", "info", "日志级别，debug、`info`、warn 或 error")
+	globalFlags.BoolVar(&quiet, "quiet", false, "只输出警告和错误，不显示进度，与 -log-level warn 相同")
+	globalFlags.BoolVar(&noColor, "no-color", false, "不使用颜色，设置了 NO_COLOR 环境变量或输出不是终端时也不使用")
+	globalFlags.BoolVar(&strict, "strict", false, "遇到未支持的块时使页面转换失败")
+	globalFlags.StringVar(&recordDir, "record", "", "把 Notion API 的响应录制到该目录")
+	globalFlags.StringVar(&replayDir, "replay", "", "从该目录回放录制的 Notion API 响应，不访问网络")
+
+	// 每个配置字段都有同名参数，例如 -storage.s3.bucket，优先级高于环境变量和配置文件
+	configFlags.Register(fieldFlags)
+}
+
+// addFlags 把 src 中的参数加入 dst，两边共享参数的值
+func addFlags(dst, src *flag.FlagSet) {
+	src.VisitAll(func(f *flag.Flag) {
+		dst.Var(f.Value, f.Name, f.Usage)
+	})
+}
+
+// isGlobalFlag 判断参数是否为全局参数或配置字段的参数
+func isGlobalFlag(name string) bool {
+	return globalFlags.Lookup(name) != nil || fieldFlags.Lookup(name) != nil
+}
+
+// newFlagSet 创建子命令的参数，全局参数和配置字段的参数也注册在其中
+//
+// usage 和 description 在 -h 时输出，帮助信息中只列出子命令自己的参数。
+func newFlagSet(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	addFlags(fs, globalFlags)
+	addFlags(fs, fieldFlags)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "用法: notion2md %s\n\n%s\n", usage, description)
+
+		own := flag.NewFlagSet(name, flag.ContinueOnError)
+		own.SetOutput(w)
+		fs.VisitAll(func(f *flag.Flag) {
+			if !isGlobalFlag(f.Name) {
+				own.Var(f.Value, f.Name, f.Usage)
+				own.Lookup(f.Name).DefValue = f.DefValue
+			}
+		})
+		if hasFlags(own) {
+			fmt.Fprintf(w, "\n参数:\n")
+			own.PrintDefaults()
+		}
+		fmt.Fprintf(w, "\n全局参数和配置参数见 notion2md -h\n")
+	}
+	return fs
+}
+
+// hasFlags 判断 fs 中是否注册了参数
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// parseArgs 解析子命令的参数，并按全局参数设置日志
+func parseArgs(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	return setupLogging(os.Stderr)
+}
+
+// addDryRunFlag 为支持预览的子命令注册 -dry-run，写在子命令之前解析到的值不会被重置
+func addDryRunFlag(fs *flag.FlagSet, usage string) {
+	fs.BoolVar(&dryRun, "dry-run", dryRun, usage)
+}
+
+// run 解析全局参数并执行子命令，返回进程的退出码
+func run(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("notion2md", flag.ContinueOnError)
+	addFlags(fs, globalFlags)
+	addFlags(fs, fieldFlags)
+	// 旧版本中 -dry-run 是全局参数，仍然可以写在子命令之前
+	addDryRunFlag(fs, "只预览会发生的变化，与在子命令之后使用相同")
+	fs.Usage = func() { printUsage(fs.Output()) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitConfig
+	}
+	// 子命令的参数解析失败时也按全局参数输出日志
+	if err := setupLogging(os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitConfig
+	}
+
+	name, args := "sync", fs.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	// help <命令> 与 <命令> -h 相同
+	if name == "help" {
+		if len(args) == 0 {
+			printUsage(os.Stdout)
+			return exitOK
+		}
+		name, args = args[0], []string{"-h"}
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "未知的命令: %s\n\n", name)
+		printUsage(os.Stderr)
+		return exitConfig
+	}
+
+	// 不支持预览的命令没有注册 -dry-run，写在命令之前时在这里检查
+	if dryRun && !cmd.DryRun {
+		fmt.Fprintf(os.Stderr, "%s 不支持 -dry-run\n", name)
+		return exitConfig
+	}
+
+	err := cmd.Run(ctx, args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		reportError(name, err)
+	}
+	return exitCode(err)
+}
+
+// findCommand 按名称查找子命令
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].Name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// printUsage 输出所有子命令、全局参数和配置参数
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "用法: notion2md [全局参数] [命令] [参数]\n\n把 Notion 数据库中的文章转换为静态站点的内容。\n\n命令:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(w, "\n用 notion2md help <命令> 或 notion2md <命令> -h 查看命令的参数。\n\n全局参数（可以写在命令之前或之后）:\n")
+
+	globalFlags.SetOutput(w)
+	globalFlags.PrintDefaults()
+
+	fmt.Fprintf(w, "\n配置参数（优先级高于环境变量和配置文件）:\n")
+	fieldFlags.SetOutput(w)
+	fieldFlags.PrintDefaults()
+
+	fmt.Fprintf(w, "\n退出码: 0 成功，1 部分来源或文章失败，2 配置或参数有误\n")
+}
+
+// exitError 为错误指定退出码
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// configError 表示配置或命令行参数有误，退出码为 2
+func configError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: exitConfig, err: err}
+}
+
+// exitCode 返回错误对应的退出码
+func exitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	var validationErr *config.ValidationError
+	var parseErr *config.ParseError
+	if errors.As(err, &validationErr) || errors.As(err, &parseErr) {
+		return exitConfig
+	}
+	return exitFailure
+}
+
+// reportError 输出命令失败的原因，配置中的每个问题单独输出一条
+func reportError(name string, err error) {
+	var validationErr *config.ValidationError
+	if errors.As(err, &validationErr) {
+		for _, problem := range validationErr.Problems {
+			slog.Error("配置有误", "command", name, "problem", problem)
+		}
+		return
+	}
+	slog.Error(err.Error(), "command", name)
+}
+
+// setupLogging 按全局参数设置默认的日志处理器，log 包的输出也经过该处理器
+func setupLogging(w io.Writer) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("不支持的日志级别: %s，应为 debug、info、warn 或 error", logLevel)
+	}
+	if quiet && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	options := &slog.HandlerOptions{Level: level}
+	switch logFormat {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(w, options)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, options)))
+	default:
+		return fmt.Errorf("不支持的日志格式: %s，应为 text 或 json", logFormat)
+	}
+	return nil
+}
+
+// showProgress 判断是否显示进度条，JSON 日志、-quiet 和标准错误不是终端（例如 CI）时不显示
+func showProgress() bool {
+	return !quiet && logFormat == "text" && isTerminal(os.Stderr)
+}
+
+// useColor 判断是否在 f 的输出中使用颜色
+func useColor(f *os.File) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// isTerminal 判断 f 是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ANSI 颜色
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+	colorCyan   = "36"
+)
+
+// colorize 在 enabled 为 true 时为文本加上颜色
+func colorize(enabled bool, color, s string) string {
+	if !enabled {
+		return s
+	}
+	return "\x1b[" + color + "m" + s + "\x1b[0m"
+}
+
+// extraArgs 在有多余的位置参数时返回错误
+func extraArgs(fs *flag.FlagSet) error {
+	if fs.NArg() > 0 {
+		return fmt.Errorf("多余的参数: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
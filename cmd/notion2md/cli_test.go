@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"notion2md/pkg/config"
+)
+
+// resetGlobals 在测试结束后恢复全局参数
+func resetGlobals(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		globalFlags.VisitAll(func(f *flag.Flag) { f.Value.Set(f.DefValue) })
+		dryRun = false
+		configFlags = &config.Flags{}
+		fieldFlags = flag.NewFlagSet("notion2md", flag.ContinueOnError)
+		configFlags.Register(fieldFlags)
+	})
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tt := range []struct {
+		err  error
+		want int
+	}{
+		{nil, exitOK},
+		{configError(flag.ErrHelp), exitOK},
+		{errors.New("1 个来源失败，0 篇文章失败"), exitFailure},
+		{configError(errors.New("多余的参数: x")), exitConfig},
+		{&config.ValidationError{Problems: []string{"未设置 databaseID"}}, exitConfig},
+		{fmt.Errorf("加载配置失败: %w", &config.ParseError{Path: "a.yaml", Err: errors.New("x")}), exitConfig},
+	} {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d，期望 %d", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	resetGlobals(t)
+	t.Setenv("NOTION2MD_CONFIG", "")
+	// 工作目录中没有配置文件和 .env
+	wd, _ := os.Getwd()
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, tt := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"unknown"}, exitConfig},
+		{[]string{"-no-such-flag"}, exitConfig},
+		{[]string{"page"}, exitConfig},
+		{[]string{"sync", "extra"}, exitConfig},
+		{[]string{"-log-format", "xml", "sync"}, exitConfig},
+		{[]string{"-log-level", "verbose", "sync"}, exitConfig},
+		// 不支持预览的命令
+		{[]string{"-dry-run", "watch"}, exitConfig},
+		// 没有配置时检查失败
+		{[]string{"sync"}, exitConfig},
+		{[]string{"gc", "-h"}, exitOK},
+	} {
+		if got := run(context.Background(), tt.args); got != tt.want {
+			t.Errorf("run(%q) = %d，期望 %d", tt.args, got, tt.want)
+		}
+		dryRun = false
+		logFormat = "text"
+		logLevel = "info"
+	}
+}
+
+func TestGlobalFlagsAfterCommand(t *testing.T) {
+	resetGlobals(t)
+
+	options, err := parseWatchFlags([]string{"-interval", "1m", "-config", "site.yaml", "-quiet", "-storage.type", "s3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.Interval.Minutes() != 1 || configFile != "site.yaml" || !quiet {
+		t.Errorf("参数解析错误: %+v %q %v", options, configFile, quiet)
+	}
+	var c config.Config
+	if err := configFlags.Apply(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Storage.Type != "s3" {
+		t.Errorf("storage.type = %q", c.Storage.Type)
+	}
+}
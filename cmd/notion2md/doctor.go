@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/media"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/aws/smithy-go"
+	"github.com/jomei/notionapi"
+)
+
+func runDoctorCommand(ctx context.Context, args []string) error {
+	flags := newFlagSet("doctor", "doctor", `检查配置、Notion 令牌、数据库是否共享给集成、属性和状态选项、模板、内容目录和媒体存储，
+逐项输出结果。有检查项失败时退出码为 1。`)
+	if err := parseArgs(flags, args); err != nil {
+		return configError(err)
+	}
+	if err := extraArgs(flags); err != nil {
+		return configError(err)
+	}
+
+	// doctor 不校验配置就开始检查，配置的问题作为检查结果输出
+	c, err := loadConfig(false)
+	if err != nil {
+		return err
+	}
+	client, clientErr := newClient(c)
+	if !runDoctor(ctx, client, clientErr, c, os.Stdout) {
+		return errors.New("检查没有通过")
+	}
+	return nil
+}
+
+// doctor 逐项检查配置、Notion 权限和输出位置，并输出每一项的结果
+type doctor struct {
+	ctx      context.Context
+	client   *notionapi.Client
+	out      io.Writer
+	color    bool
+	failures int
+	warnings int
+}
+
+func (d *doctor) pass(name, format string, args ...interface{}) {
+	d.print(colorGreen, "[通过]", name, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) warn(name, format string, args ...interface{}) {
+	d.warnings++
+	d.print(colorYellow, "[警告]", name, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) fail(name, format string, args ...interface{}) {
+	d.failures++
+	d.print(colorRed, "[失败]", name, fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) print(color, mark, name, message string) {
+	fmt.Fprintf(d.out, "%s %s: %s\n", colorize(d.color, color, mark), name, message)
+}
+
+// runDoctor 检查配置能否正常同步，全部通过（允许警告）时返回 true
+//
+// client 为 nil 时 clientErr 说明原因，跳过需要访问 Notion 的检查。
+func runDoctor(ctx context.Context, client *notionapi.Client, clientErr error, c *config.Config, out io.Writer) bool {
+	d := &doctor{ctx: ctx, client: client, out: out}
+	if f, ok := out.(*os.File); ok {
+		d.color = useColor(f)
+	}
+
+	var validation *config.ValidationError
+	if err := c.Validate(); errors.As(err, &validation) {
+		for _, problem := range validation.Problems {
+			d.fail("配置", "%s", problem)
+		}
+	} else if err != nil {
+		d.fail("配置", "%v", err)
+	} else {
+		d.pass("配置", "配置项完整")
+	}
+
+	if client == nil {
+		d.fail("Notion 令牌", "%v，在 https://www.notion.so/my-integrations 中创建集成并复制 Internal Integration Secret", clientErr)
+	} else if user, err := client.User.Me(ctx); err != nil {
+		d.fail("Notion 令牌", "%v", notionError(err))
+	} else {
+		d.pass("Notion 令牌", "集成 %s 可用", user.Name)
+	}
+
+	for _, source := range c.SourceConfigs() {
+		prefix := ""
+		if len(c.Sources) > 0 {
+			prefix = fmt.Sprintf("[%s] ", sourceName(source))
+		}
+		if client != nil && source.DatabaseID != "" {
+			d.checkDatabase(prefix, source)
+		}
+		d.checkTemplates(prefix, source)
+		d.checkWritable(prefix+"内容目录", source.Content.Folder)
+		d.checkStorage(prefix, source)
+	}
+
+	fmt.Fprintf(out, "\n%d 项失败，%d 项警告\n", d.failures, d.warnings)
+	return d.failures == 0
+}
+
+// propertyCheck 是一个需要检查类型的属性
+type propertyCheck struct {
+	key      string
+	name     string
+	types    []notionapi.PropertyConfigType
+	required bool
+}
+
+// checkDatabase 检查数据库能否访问、配置的属性是否存在且类型正确、状态取值是否存在
+func (d *doctor) checkDatabase(prefix string, c *config.Config) {
+	database, err := d.client.Database.Get(d.ctx, notionapi.DatabaseID(c.DatabaseID))
+	if err != nil {
+		d.fail(prefix+"数据库", "%v", notionError(err))
+		return
+	}
+	d.pass(prefix+"数据库", "可以访问「%s」", plainText(database.Title))
+
+	props := c.Notion.Properties
+	checks := []propertyCheck{
+		{"title", notion.TitleProperty(c), []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeTitle}, true},
+		{"status", notion.StatusProperty(c), []notionapi.PropertyConfigType{notionapi.PropertyConfigStatus, notionapi.PropertyConfigTypeSelect}, len(c.Notion.Query.Filter) == 0},
+		{"tags", props.Tags, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeMultiSelect}, false},
+		{"categories", props.Categories, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeSelect, notionapi.PropertyConfigTypeMultiSelect}, false},
+		{"description", props.Description, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeRichText}, false},
+		{"metaTitle", props.MetaTitle, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeRichText}, false},
+		{"slug", props.Slug, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeRichText}, false},
+		{"toc", props.Toc, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeCheckbox}, false},
+		{"comments", props.Comments, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeCheckbox}, false},
+		{"publishDate", props.PublishDate, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeDate}, false},
+		{"expiryDate", props.ExpiryDate, []notionapi.PropertyConfigType{notionapi.PropertyConfigTypeDate}, false},
+	}
+	for _, check := range checks {
+		if check.name == "" {
+			continue
+		}
+		name := fmt.Sprintf("%s属性 %s", prefix, check.key)
+		prop, ok := database.Properties[check.name]
+		switch {
+		case !ok && check.required:
+			d.fail(name, "数据库中没有属性「%s」，在 notion.properties.%s 中设置为以下属性之一: %s", check.name, check.key, propertiesOfType(database, check.types))
+		case !ok:
+			d.warn(name, "数据库中没有属性「%s」，修改 notion.properties.%s 或在数据库中添加该属性", check.name, check.key)
+		case !hasType(prop.GetType(), check.types):
+			d.fail(name, "属性「%s」的类型是 %s，应为 %s", check.name, prop.GetType(), joinTypes(check.types))
+		default:
+			d.pass(name, "属性「%s」（%s）", check.name, prop.GetType())
+		}
+	}
+
+	if prop, ok := database.Properties[notion.StatusProperty(c)]; ok {
+		d.checkStatusOptions(prefix, c, prop)
+	}
+	d.checkCategories(prefix, c, database)
+}
+
+// checkStatusOptions 检查 notion.status 中的取值是否是状态属性的选项
+func (d *doctor) checkStatusOptions(prefix string, c *config.Config, prop notionapi.PropertyConfig) {
+	options := propertyOptions(prop)
+	if options == nil {
+		return
+	}
+	status := c.Notion.Status
+	for _, field := range []struct{ key, value string }{
+		{"draft", status.Draft},
+		{"ready", status.Ready},
+		{"published", status.Published},
+		{"toDelete", status.ToDelete},
+		{"deleted", status.Deleted},
+	} {
+		if field.value == "" {
+			continue
+		}
+		name := fmt.Sprintf("%s状态 %s", prefix, field.key)
+		if contains(options, field.value) {
+			d.pass(name, "「%s」", field.value)
+			continue
+		}
+		d.fail(name, "「%s」不是状态属性「%s」的选项，在 notion.status.%s 中设置为以下选项之一: %s",
+			field.value, notion.StatusProperty(c), field.key, strings.Join(options, "、"))
+	}
+}
+
+// checkCategories 检查分类属性的选项是否都有映射，没有映射的分类下的文章会被跳过
+func (d *doctor) checkCategories(prefix string, c *config.Config, database *notionapi.Database) {
+	names := []string{"Category", "Categories"}
+	if c.Notion.Properties.Categories != "" {
+		names = []string{c.Notion.Properties.Categories}
+	}
+	for _, name := range names {
+		options := propertyOptions(database.Properties[name])
+		if options == nil {
+			continue
+		}
+		var unmapped []string
+		for _, option := range options {
+			if _, ok := c.Notion.CategoryMap[option]; !ok {
+				unmapped = append(unmapped, option)
+			}
+		}
+		if len(unmapped) > 0 {
+			d.warn(prefix+"分类映射", "分类 %s 没有在 notion.categoryMap 中映射，这些分类下的文章会被跳过", strings.Join(unmapped, "、"))
+		} else {
+			d.pass(prefix+"分类映射", "属性「%s」的 %d 个分类都有映射", name, len(options))
+		}
+		return
+	}
+}
+
+// checkTemplates 检查文章模板和块模板能否解析
+func (d *doctor) checkTemplates(prefix string, c *config.Config) {
+	// 不存在的模板文件已经在检查配置时报告
+	path := converterTemplate(c)
+	switch {
+	case path == "" || !fileExists(path):
+	case c.Target == "html":
+		if _, err := htmltemplate.ParseFiles(path); err != nil {
+			d.fail(prefix+"模板", "html.template 解析失败: %v", err)
+		} else {
+			d.pass(prefix+"模板", "%s", path)
+		}
+	default:
+		if _, err := template.ParseFiles(path); err != nil {
+			d.fail(prefix+"模板", "%s 解析失败，检查模板中的 {{ }} 是否成对: %v", path, err)
+		} else {
+			d.pass(prefix+"模板", "%s", path)
+		}
+	}
+
+	blockTypes := make([]string, 0, len(c.Blocks.Templates))
+	for blockType := range c.Blocks.Templates {
+		blockTypes = append(blockTypes, blockType)
+	}
+	sort.Strings(blockTypes)
+	for _, blockType := range blockTypes {
+		path := c.Blocks.Templates[blockType]
+		if !fileExists(path) {
+			continue
+		}
+		if _, err := notion.NewTemplateRenderer(path); err != nil {
+			d.fail(prefix+"块模板 "+blockType, "%v", err)
+		} else {
+			d.pass(prefix+"块模板 "+blockType, "%s", path)
+		}
+	}
+}
+
+// checkWritable 在目录中创建并删除一个临时文件，目录不存在时检查最近的已存在的上级目录
+func (d *doctor) checkWritable(name, dir string) {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil && !info.IsDir() {
+			d.fail(name, "%s 不是目录", existing)
+			return
+		}
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			d.fail(name, "无法访问 %s: %v", existing, err)
+			return
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".notion2md-check-*")
+	if err != nil {
+		d.fail(name, "目录 %s 不可写，检查目录的权限和所有者: %v", existing, err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	if existing != dir {
+		d.pass(name, "%s 不存在，同步时会在可写的 %s 中创建", dir, existing)
+		return
+	}
+	d.pass(name, "%s 可写", dir)
+}
+
+// checkStorage 检查媒体存储，本地存储检查目录可写，S3 上传并删除测试对象
+func (d *doctor) checkStorage(prefix string, c *config.Config) {
+	switch c.Storage.Type {
+	case "local":
+		d.checkWritable(prefix+"媒体目录", c.Storage.Local.Path)
+	case "s3":
+		if c.Storage.S3.Bucket == "" || c.Storage.S3.Region == "" {
+			return
+		}
+		handler, err := media.NewS3Handler(d.ctx, c.Storage.S3.Bucket, c.Storage.S3.Region, c.Storage.S3.PathPrefix, c.Storage.S3.URLPrefix, c.Storage.S3.Endpoint, c.Storage.S3.ForcePathStyle)
+		if err == nil {
+			err = handler.Check()
+		}
+		if err != nil {
+			d.fail(prefix+"S3", "%v%s", err, s3Hint(err))
+			return
+		}
+		d.pass(prefix+"S3", "存储桶 %s 可以上传和删除", c.Storage.S3.Bucket)
+	}
+}
+
+// notionError 为常见的 Notion API 错误附加处理方法
+func notionError(err error) error {
+	var apiErr *notionapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.Code {
+	case "unauthorized":
+		return fmt.Errorf("%w。NOTION_SECRET 无效或已被重置，在 https://www.notion.so/my-integrations 中复制集成的 Internal Integration Secret", err)
+	case "object_not_found":
+		return fmt.Errorf("%w。数据库不存在或没有共享给集成：在 Notion 中打开数据库，点击右上角的 ··· → Connections，添加该集成；并确认 databaseID 是数据库而不是页面的 ID", err)
+	case "restricted_resource":
+		return fmt.Errorf("%w。集成没有权限：在集成设置的 Capabilities 中开启 Read content、Update content", err)
+	case "validation_error":
+		return fmt.Errorf("%w。检查 databaseID 的格式和 notion.query 中的过滤条件", err)
+	}
+	return err
+}
+
+// s3Hint 返回常见 S3 错误的处理方法
+func s3Hint(err error) string {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return "。检查网络以及 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY 和 AWS_ENDPOINT_URL_S3"
+	}
+	switch apiErr.ErrorCode() {
+	case "NoSuchBucket":
+		return "。存储桶不存在，检查 storage.s3.bucket 和 AWS_ENDPOINT_URL_S3"
+	case "AccessDenied":
+		return "。凭证没有权限，为其授予存储桶的 s3:PutObject 和 s3:DeleteObject 权限"
+	case "InvalidAccessKeyId", "SignatureDoesNotMatch":
+		return "。凭证无效，检查 AWS_ACCESS_KEY_ID 和 AWS_SECRET_ACCESS_KEY"
+	case "PermanentRedirect", "AuthorizationHeaderMalformed":
+		return "。区域不正确，检查 storage.s3.region"
+	}
+	return ""
+}
+
+// propertyOptions 返回状态、单选或多选属性的选项，其他类型返回 nil
+func propertyOptions(prop notionapi.PropertyConfig) []string {
+	var options []notionapi.Option
+	switch prop := prop.(type) {
+	case *notionapi.StatusPropertyConfig:
+		options = prop.Status.Options
+	case *notionapi.SelectPropertyConfig:
+		options = prop.Select.Options
+	case *notionapi.MultiSelectPropertyConfig:
+		options = prop.MultiSelect.Options
+	default:
+		return nil
+	}
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// propertiesOfType 列出数据库中类型为 types 之一的属性
+func propertiesOfType(database *notionapi.Database, types []notionapi.PropertyConfigType) string {
+	var names []string
+	for name, prop := range database.Properties {
+		if hasType(prop.GetType(), types) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "（没有 " + joinTypes(types) + " 类型的属性）"
+	}
+	sort.Strings(names)
+	return strings.Join(names, "、")
+}
+
+func hasType(t notionapi.PropertyConfigType, types []notionapi.PropertyConfigType) bool {
+	for _, candidate := range types {
+		if t == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func joinTypes(types []notionapi.PropertyConfigType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, " 或 ")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
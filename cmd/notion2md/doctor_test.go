@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/fixture"
+)
+
+const testSchema = `{
+	"title": [{"type": "text", "text": {"content": "博客"}, "plain_text": "博客"}],
+	"properties": {
+		"Name": {"id": "title", "type": "title", "title": {}},
+		"Status": {"id": "s", "type": "status", "status": {"options": [
+			{"id": "1", "name": "Draft"},
+			{"id": "2", "name": "Ready"},
+			{"id": "3", "name": "Published"}
+		], "groups": []}},
+		"Category": {"id": "c", "type": "select", "select": {"options": [{"name": "技术"}, {"name": "生活"}]}},
+		"Publish Date": {"id": "p", "type": "rich_text", "rich_text": {}}
+	}
+}`
+
+func TestDoctor(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.SetDatabase(testDatabase, testSchema)
+
+	config := testConfig(t)
+	// 状态 To Delete 和 Deleted 不是数据库中的选项，Publish Date 不是日期属性
+	var out strings.Builder
+	if runDoctor(context.Background(), newTestClient(server, config), nil, config, &out) {
+		t.Errorf("检查应失败:\n%s", out.String())
+	}
+
+	for _, want := range []string{
+		"[通过] Notion 令牌",
+		"[通过] 数据库: 可以访问「博客」",
+		"[通过] 属性 title: 属性「Name」（title）",
+		"[失败] 属性 publishDate: 属性「Publish Date」的类型是 rich_text，应为 date",
+		"[通过] 状态 ready: 「Ready」",
+		"[失败] 状态 toDelete: 「To Delete」不是状态属性「Status」的选项，在 notion.status.toDelete 中设置为以下选项之一: Draft、Ready、Published",
+		"[警告] 分类映射: 分类 生活 没有在 notion.categoryMap 中映射",
+		"[通过] 模板",
+		"[通过] 内容目录",
+		"3 项失败，1 项警告",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("输出中没有 %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDoctorDatabaseNotShared(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+
+	config := testConfig(t)
+	config.Notion.Properties.Title = "标题"
+	var out strings.Builder
+	runDoctor(context.Background(), newTestClient(server, config), nil, config, &out)
+	if !strings.Contains(out.String(), "[失败] 数据库") || !strings.Contains(out.String(), "Connections") {
+		t.Errorf("没有提示共享数据库:\n%s", out.String())
+	}
+
+	server.SetDatabase(testDatabase, testSchema)
+	out.Reset()
+	runDoctor(context.Background(), newTestClient(server, config), nil, config, &out)
+	if !strings.Contains(out.String(), "[失败] 属性 title: 数据库中没有属性「标题」，在 notion.properties.title 中设置为以下属性之一: Name") {
+		t.Errorf("没有提示标题属性:\n%s", out.String())
+	}
+}
+
+func TestDoctorWithoutClient(t *testing.T) {
+	config := testConfig(t)
+	config.DatabaseID = ""
+	// 内容目录不存在时检查已存在的上级目录
+	config.Content.Folder = filepath.Join(t.TempDir(), "a", "b")
+	var out strings.Builder
+	if runDoctor(context.Background(), nil, errors.New("未设置 NOTION_SECRET 环境变量"), config, &out) {
+		t.Error("检查应失败")
+	}
+	for _, want := range []string{
+		"[失败] 配置: 未设置 databaseID",
+		"[失败] Notion 令牌: 未设置 NOTION_SECRET 环境变量",
+		"不存在，同步时会在可写的",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("输出中没有 %q:\n%s", want, out.String())
+		}
+	}
+	if _, err := os.Stat(config.Content.Folder); err == nil {
+		t.Error("检查不应创建内容目录")
+	}
+}
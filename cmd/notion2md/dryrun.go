@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/media"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// diffWriter 不写入文件，只报告文件会被创建还是修改，修改时输出统一格式的差异
+type diffWriter struct {
+	w io.Writer
+}
+
+func (d *diffWriter) WriteFile(path string, data []byte) error {
+	old, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		fmt.Fprintf(d.w, "  创建: %s\n", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	if bytes.Equal(old, data) {
+		fmt.Fprintf(d.w, "  无变化: %s\n", path)
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(old)),
+		B:        difflib.SplitLines(string(data)),
+		FromFile: path,
+		ToFile:   path,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("生成差异失败: %w", err)
+	}
+	if f, ok := d.w.(*os.File); ok && useColor(f) {
+		diff = colorDiff(diff)
+	}
+	fmt.Fprintf(d.w, "  修改: %s\n%s", path, diff)
+	return nil
+}
+
+// colorDiff 为差异中增加和删除的行加上颜色
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorize(true, colorCyan, strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorize(true, colorGreen, strings.TrimSuffix(line, "\n")) + "\n"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorize(true, colorRed, strings.TrimSuffix(line, "\n")) + "\n"
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// RemoveFile 报告文件会被删除，文件不存在时不输出
+func (d *diffWriter) RemoveFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	fmt.Fprintf(d.w, "  删除: %s\n", path)
+	return nil
+}
+
+// previewMediaHandler 不下载也不上传媒体文件，只记录下来
+//
+// 页面中的链接与来源的存储实际保存后返回的链接相同，已有文件中的链接不会显示为修改；
+// 链接没有文件扩展名、保存后的文件名无法预先确定时保留原始链接。
+type previewMediaHandler struct {
+	storage  config.StorageConfig
+	category string
+	article  string
+	// keepLinks 为 true 时始终保留原始链接，用于不保存媒体的 -stdout
+	keepLinks bool
+	saved     []string
+}
+
+func (h *previewMediaHandler) SetContext(category, article string) {
+	h.category = category
+	h.article = article
+}
+
+func (h *previewMediaHandler) SaveMedia(url string) (string, error) {
+	h.saved = append(h.saved, url)
+	if h.keepLinks {
+		return url, nil
+	}
+	link, ok := "", false
+	switch h.storage.Type {
+	case "local":
+		link, ok = media.LocalURL(h.storage.Local.URLPrefix, h.category, h.article, url)
+	case "s3":
+		link, ok = media.S3URL(h.storage.S3.URLPrefix, url)
+	}
+	if !ok {
+		return url, nil
+	}
+	return link, nil
+}
+
+func (h *previewMediaHandler) SupportedTypes() []string {
+	return []string{
+		"image/jpeg",
+		"image/png",
+		"image/gif",
+		"image/webp",
+		"video/mp4",
+		"video/webm",
+		"audio/mpeg",
+		"audio/wav",
+		"application/pdf",
+	}
+}
+
+// report 输出当前页面会保存的媒体，并清空记录
+func (h *previewMediaHandler) report(w io.Writer) {
+	for _, url := range h.saved {
+		fmt.Fprintf(w, "  媒体: %s\n", url)
+	}
+	h.saved = nil
+}
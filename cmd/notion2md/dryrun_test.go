@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+)
+
+func TestDiffWriter(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "post.md")
+	if err := os.WriteFile(existing, []byte("title\nold line\nend\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	w := &diffWriter{w: &out}
+	if err := w.WriteFile(filepath.Join(dir, "new.md"), []byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteFile(existing, []byte("title\nold line\nend\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteFile(existing, []byte("title\nnew line\nend\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"创建: " + filepath.Join(dir, "new.md"),
+		"无变化: " + existing,
+		"修改: " + existing,
+		"-old line\n+new line\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("输出缺少 %q:\n%s", want, got)
+		}
+	}
+
+	out.Reset()
+	if err := w.RemoveFile(existing); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.RemoveFile(filepath.Join(dir, "missing.md")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "  删除: "+existing+"\n" {
+		t.Errorf("删除的输出不一致: %q", out.String())
+	}
+
+	// 预览不会修改文件
+	data, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "title\nold line\nend\n" {
+		t.Errorf("文件被修改: %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.md")); err == nil {
+		t.Error("预览创建了文件")
+	}
+}
+
+func TestPreviewMediaHandler(t *testing.T) {
+	var storage config.StorageConfig
+	storage.Local.URLPrefix = "/images"
+	storage.S3.URLPrefix = "https://cdn.example.com"
+
+	const notionURL = "https://files.notion.so/a.png?x=1"
+	for _, tt := range []struct {
+		storageType string
+		keepLinks   bool
+		url         string
+		want        string
+	}{
+		// 与实际保存后的链接相同
+		{"local", false, notionURL, "/images/tech/ni-hao/a.png"},
+		{"s3", false, notionURL, "https://cdn.example.com/a.png"},
+		// 文件名取决于 Content-Type 时保留原始链接
+		{"local", false, "https://files.notion.so/a?x=1", "https://files.notion.so/a?x=1"},
+		{"local", true, notionURL, notionURL},
+	} {
+		storage.Type = tt.storageType
+		h := &previewMediaHandler{storage: storage, keepLinks: tt.keepLinks}
+		h.SetContext("tech", "ni-hao")
+		got, err := h.SaveMedia(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("%s SaveMedia(%q) = %q, %v，期望 %q", tt.storageType, tt.url, got, err, tt.want)
+		}
+
+		var out bytes.Buffer
+		h.report(&out)
+		if out.String() != "  媒体: "+tt.url+"\n" {
+			t.Errorf("输出不一致: %q", out.String())
+		}
+		if len(h.saved) != 0 {
+			t.Error("report 后应清空记录")
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"notion2md/pkg/config"
+)
+
+func runGC(ctx context.Context, args []string) error {
+	flags := newFlagSet("gc", "gc [参数]", `删除本地媒体目录（storage.local.path）中没有被任何来源的内容目录（content.folder）引用的文件，
+以及因此变空的文章目录。只清理 notion2md 保存媒体的 <分类>/<文章>/ 目录，分类为 notion.categoryMap
+中映射后的目录或 uncategorized，媒体目录中的其他文件不会删除。以 . 开头的文件（例如 .gitkeep）不会删除，
+使用 S3 存储的来源不会清理。建议先用 -dry-run 确认。`)
+	addDryRunFlag(flags, "只列出会删除的文件，不删除")
+	if err := parseArgs(flags, args); err != nil {
+		return configError(err)
+	}
+	if err := extraArgs(flags); err != nil {
+		return configError(err)
+	}
+
+	c, err := loadConfig(true)
+	if err != nil {
+		return err
+	}
+	_, err = collectGarbage(c.SourceConfigs(), dryRun, os.Stdout)
+	return err
+}
+
+// mediaStore 是一个本地媒体目录，以及使用该目录的来源配置的 URL 前缀和分类目录
+type mediaStore struct {
+	path        string
+	urlPrefixes []string
+	// categories 是本地处理器会在该目录下创建的分类目录
+	categories map[string]bool
+}
+
+// owned 判断相对路径为 rel 的文件是否位于本地处理器创建的 <分类>/<文章>/ 目录中
+func (m *mediaStore) owned(rel string) bool {
+	parts := strings.Split(rel, "/")
+	return len(parts) == 3 && m.categories[parts[0]]
+}
+
+// referenced 判断媒体目录中相对路径为 rel 的文件是否出现在内容中
+//
+// 本地媒体处理器返回的链接为 URL 前缀加上 / 和相对路径。
+func (m *mediaStore) referenced(content, rel string) bool {
+	for _, prefix := range m.urlPrefixes {
+		if strings.Contains(content, prefix+"/"+rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectGarbage 删除本地媒体目录中没有被内容引用的文件，返回删除（预览时为会删除）的文件数
+func collectGarbage(sources []*config.Config, dryRun bool, out io.Writer) (int, error) {
+	var stores []*mediaStore
+	var folders []string
+	for _, source := range sources {
+		if !contains(folders, source.Content.Folder) {
+			folders = append(folders, source.Content.Folder)
+		}
+		if source.Storage.Type != "local" {
+			continue
+		}
+		store := findStore(stores, source.Storage.Local.Path)
+		if store == nil {
+			store = &mediaStore{
+				path: source.Storage.Local.Path,
+				// 没有分类的文章保存在 uncategorized 下
+				categories: map[string]bool{"uncategorized": true},
+			}
+			stores = append(stores, store)
+		}
+		for _, dir := range source.Notion.CategoryMap {
+			store.categories[dir] = true
+		}
+		if !contains(store.urlPrefixes, source.Storage.Local.URLPrefix) {
+			store.urlPrefixes = append(store.urlPrefixes, source.Storage.Local.URLPrefix)
+		}
+	}
+	if len(stores) == 0 {
+		return 0, errors.New("没有使用本地存储的来源")
+	}
+
+	content, err := readContent(folders, stores)
+	if err != nil {
+		return 0, err
+	}
+
+	verb := "删除"
+	if dryRun {
+		verb = "将删除"
+	}
+	removed := 0
+	var size int64
+	for _, store := range stores {
+		err := filepath.WalkDir(store.path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// 还没有保存过媒体时目录不存在
+				if path == store.path && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipAll
+				}
+				return err
+			}
+			rel, err := filepath.Rel(store.path, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if entry.IsDir() {
+				// 只进入分类目录和其中的文章目录
+				depth := strings.Count(rel, "/") + 1
+				if path != store.path && (depth > 2 || !store.categories[strings.Split(rel, "/")[0]]) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if strings.HasPrefix(entry.Name(), ".") || !store.owned(rel) || store.referenced(content, rel) {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "  %s: %s\n", verb, path)
+			removed++
+			size += info.Size()
+			return nil
+		})
+		if err != nil {
+			return removed, fmt.Errorf("清理媒体目录失败: %w", err)
+		}
+		if !dryRun {
+			for category := range store.categories {
+				removeEmptyDirs(filepath.Join(store.path, category))
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "%s %d 个未引用的媒体文件，共 %s\n", verb, removed, formatSize(size))
+	return removed, nil
+}
+
+// findStore 按目录查找媒体目录
+func findStore(stores []*mediaStore, path string) *mediaStore {
+	for _, store := range stores {
+		if filepath.Clean(store.path) == filepath.Clean(path) {
+			return store
+		}
+	}
+	return nil
+}
+
+// readContent 读取内容目录中的所有文件，跳过其中的媒体目录和以 . 开头的目录
+//
+// 内容目录不存在时返回错误，防止在错误的工作目录下运行时删除所有媒体。
+func readContent(folders []string, stores []*mediaStore) (string, error) {
+	var content strings.Builder
+	for _, folder := range folders {
+		err := filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if path != folder && (strings.HasPrefix(entry.Name(), ".") || findStore(stores, path) != nil) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			content.Write(data)
+			content.WriteByte('\n')
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("读取内容目录失败: %w", err)
+		}
+	}
+	return content.String(), nil
+}
+
+// removeEmptyDirs 删除 root 下的空目录，root 本身保留
+func removeEmptyDirs(root string) {
+	var dirs []string
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() && path != root {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	// 子目录排在上级目录之后，倒序删除时先删除子目录；非空目录删除失败，直接忽略
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+}
+
+// formatSize 把字节数格式化为便于阅读的大小
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"notion2md/pkg/config"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	c := testConfig(t)
+	media := c.Storage.Local.Path
+	writeTestFile(t, filepath.Join(c.Content.Folder, "tech", "ni-hao.md"), "![图](/images/tech/ni-hao/a.png)\n")
+	writeTestFile(t, filepath.Join(media, "tech", "ni-hao", "a.png"), "a")
+	writeTestFile(t, filepath.Join(media, "tech", "ni-hao", "b.png"), "b")
+	writeTestFile(t, filepath.Join(media, "tech", "jiu-wen", "c.png"), "c")
+	writeTestFile(t, filepath.Join(media, ".gitkeep"), "")
+	// 不在 <分类>/<文章>/ 目录中的文件不是 notion2md 保存的，不会删除
+	outside := []string{
+		filepath.Join(media, "logo.png"),
+		filepath.Join(media, "tech", "banner.png"),
+		filepath.Join(media, "theme", "ni-hao", "bg.png"),
+		filepath.Join(media, "tech", "ni-hao", "extra", "d.png"),
+	}
+	for _, path := range outside {
+		writeTestFile(t, path, "x")
+	}
+	if err := os.MkdirAll(filepath.Join(media, "theme", "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 预览时不删除
+	removed, err := collectGarbage([]*config.Config{c}, true, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Errorf("预览删除 %d 个文件，期望 2", removed)
+	}
+	if _, err := os.Stat(filepath.Join(media, "tech", "jiu-wen", "c.png")); err != nil {
+		t.Errorf("预览时不应删除文件: %v", err)
+	}
+
+	if removed, err = collectGarbage([]*config.Config{c}, false, io.Discard); err != nil || removed != 2 {
+		t.Fatalf("删除 %d 个文件（%v），期望 2", removed, err)
+	}
+	for path, exists := range map[string]bool{
+		filepath.Join(media, "tech", "ni-hao", "a.png"): true,
+		filepath.Join(media, "tech", "ni-hao", "b.png"): false,
+		filepath.Join(media, "tech", "jiu-wen"):         false,
+		filepath.Join(media, ".gitkeep"):                true,
+		filepath.Join(media, "theme", "empty"):          true,
+	} {
+		if _, err := os.Stat(path); (err == nil) != exists {
+			t.Errorf("%s 存在: %v，期望 %v", path, err == nil, exists)
+		}
+	}
+
+	for _, path := range outside {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s 不应删除: %v", path, err)
+		}
+	}
+
+	// 内容目录不存在时不删除任何文件
+	c.Content.Folder = filepath.Join(t.TempDir(), "missing")
+	if _, err := collectGarbage([]*config.Config{c}, false, io.Discard); err == nil {
+		t.Error("内容目录不存在时应返回错误")
+	}
+}
@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+	"github.com/mozillazg/go-pinyin"
+)
+
+// initOptions 是 init 子命令的参数
+type initOptions struct {
+	DatabaseID string
+	Output     string
+	Force      bool
+}
+
+func runInit(ctx context.Context, args []string) error {
+	options, err := parseInitFlags(args)
+	if err != nil {
+		return configError(err)
+	}
+	// init 生成配置文件，不读取也不检查已有的配置
+	if err := loadEnvFile(); err != nil {
+		return configError(fmt.Errorf("加载环境变量文件失败: %w", err))
+	}
+	client, err := newClient(&config.Config{RateLimit: config.DefaultRateLimit})
+	if err != nil {
+		return configError(fmt.Errorf("初始化 Notion 客户端失败: %w", err))
+	}
+	if err := initConfig(ctx, client, options, os.Stdout); err != nil {
+		return fmt.Errorf("生成配置失败: %w", err)
+	}
+	return nil
+}
+
+func parseInitFlags(args []string) (*initOptions, error) {
+	options := &initOptions{}
+	var database string
+	flags := newFlagSet("init", "init -database <数据库 ID 或链接> [参数]", `读取数据库结构，推测标题、状态、分类等属性和状态选项，生成带注释的 YAML 配置文件。
+没有推测出的字段以注释的形式列出，需要 NOTION_SECRET。`)
+	flags.StringVar(&database, "database", "", "Notion 数据库 ID 或链接")
+	flags.StringVar(&options.Output, "output", "notion2md.yaml", "生成的配置文件路径，只支持 .yaml 或 .yml")
+	flags.BoolVar(&options.Force, "force", false, "覆盖已经存在的配置文件")
+	if err := parseArgs(flags, args); err != nil {
+		return nil, err
+	}
+	if err := extraArgs(flags); err != nil {
+		return nil, err
+	}
+	if database == "" {
+		return nil, errors.New("需要用 -database 指定数据库 ID 或链接")
+	}
+
+	// 数据库链接与页面链接的格式相同
+	databaseID, err := notion.ParsePageID(database)
+	if err != nil {
+		return nil, err
+	}
+	options.DatabaseID = databaseID
+	// 生成的配置带有注释，JSON 不支持注释
+	switch strings.ToLower(filepath.Ext(options.Output)) {
+	case ".yaml", ".yml":
+	default:
+		return nil, fmt.Errorf("-output 只支持 .yaml 或 .yml 文件: %s", options.Output)
+	}
+	return options, nil
+}
+
+// schemaProperty 是数据库中的一个属性
+type schemaProperty struct {
+	Name    string
+	Type    notionapi.PropertyConfigType
+	Options []string
+	// Groups 为状态属性各个分组中的选项，键为 To-do、In progress 或 Complete
+	Groups map[string][]string
+}
+
+// schemaGuess 是根据数据库结构推测的配置
+type schemaGuess struct {
+	Database    string
+	Properties  []schemaProperty
+	Config      config.Config
+	CategoryMap map[string]string
+}
+
+// initConfig 读取数据库结构，推测属性映射并写入带注释的配置文件
+func initConfig(ctx context.Context, client *notionapi.Client, options *initOptions, out io.Writer) error {
+	if _, err := os.Stat(options.Output); err == nil && !options.Force {
+		return fmt.Errorf("%s 已经存在，使用 -force 覆盖", options.Output)
+	}
+
+	database, err := client.Database.Get(ctx, notionapi.DatabaseID(options.DatabaseID))
+	if err != nil {
+		return fmt.Errorf("读取数据库失败，请确认数据库 ID 正确并且已经在数据库的 Connections 中添加了集成: %w", err)
+	}
+
+	guess := guessSchema(database)
+	guess.Config.DatabaseID = options.DatabaseID
+	printSchema(out, guess)
+
+	var buf bytes.Buffer
+	writeInitConfig(&buf, guess)
+	if err := os.WriteFile(options.Output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	fmt.Fprintf(out, "\n已写入 %s，请核对推测的属性和状态后再同步\n", options.Output)
+	return nil
+}
+
+// 推测属性时使用的名称，比较时忽略大小写、空格、下划线和连字符
+var (
+	statusNames      = []string{"status", "state", "状态", "发布状态"}
+	tagNames         = []string{"tags", "tag", "标签"}
+	categoryNames    = []string{"category", "categories", "分类", "类别", "栏目"}
+	descriptionNames = []string{"description", "summary", "excerpt", "描述", "摘要", "简介"}
+	slugNames        = []string{"slug", "permalink", "url slug", "别名"}
+	publishDateNames = []string{"publish date", "publishdate", "publish on", "发布日期", "发布时间"}
+)
+
+// 推测状态取值时使用的名称，顺序为 draft、ready、published、toDelete、deleted
+var statusRoleNames = [][]string{
+	{"draft", "草稿", "not started", "未开始", "writing", "写作中"},
+	{"ready", "ready to publish", "to publish", "待发布", "准备发布"},
+	{"published", "publish", "done", "live", "已发布", "发布"},
+	{"to delete", "unpublish", "待删除", "取消发布"},
+	{"deleted", "unpublished", "archived", "已删除", "已归档"},
+}
+
+func guessSchema(database *notionapi.Database) *schemaGuess {
+	guess := &schemaGuess{
+		Database:    plainText(database.Title),
+		CategoryMap: make(map[string]string),
+	}
+	for name, prop := range database.Properties {
+		property := schemaProperty{Name: name, Type: prop.GetType()}
+		switch prop := prop.(type) {
+		case *notionapi.StatusPropertyConfig:
+			ids := make(map[notionapi.ObjectID]string)
+			for _, option := range prop.Status.Options {
+				property.Options = append(property.Options, option.Name)
+				ids[notionapi.ObjectID(option.ID)] = option.Name
+			}
+			property.Groups = make(map[string][]string)
+			for _, group := range prop.Status.Groups {
+				for _, id := range group.OptionIDs {
+					property.Groups[group.Name] = append(property.Groups[group.Name], ids[id])
+				}
+			}
+		case *notionapi.SelectPropertyConfig:
+			for _, option := range prop.Select.Options {
+				property.Options = append(property.Options, option.Name)
+			}
+		case *notionapi.MultiSelectPropertyConfig:
+			for _, option := range prop.MultiSelect.Options {
+				property.Options = append(property.Options, option.Name)
+			}
+		}
+		guess.Properties = append(guess.Properties, property)
+	}
+	sort.Slice(guess.Properties, func(i, j int) bool {
+		return guess.Properties[i].Name < guess.Properties[j].Name
+	})
+
+	props := &guess.Config.Notion.Properties
+	if title := findProperty(guess.Properties, nil, notionapi.PropertyConfigTypeTitle); title != nil {
+		props.Title = title.Name
+	}
+	// 状态属性优先使用 Status 类型，其次是名称像状态的单选
+	status := findProperty(guess.Properties, statusNames, notionapi.PropertyConfigStatus)
+	if status == nil {
+		status = findProperty(guess.Properties, nil, notionapi.PropertyConfigStatus)
+	}
+	if status == nil {
+		status = findProperty(guess.Properties, statusNames, notionapi.PropertyConfigTypeSelect)
+	}
+	if status != nil {
+		props.Status = status.Name
+		guess.Config.Notion.Status = guessStatus(status)
+	}
+	if tags := findProperty(guess.Properties, tagNames, notionapi.PropertyConfigTypeMultiSelect); tags != nil {
+		props.Tags = tags.Name
+	}
+	if categories := findProperty(guess.Properties, categoryNames, notionapi.PropertyConfigTypeSelect, notionapi.PropertyConfigTypeMultiSelect); categories != nil {
+		props.Categories = categories.Name
+		for i, option := range categories.Options {
+			slug := categorySlug(option)
+			if slug == "" {
+				slug = fmt.Sprintf("category-%d", i+1)
+			}
+			guess.CategoryMap[option] = slug
+		}
+	}
+	if description := findProperty(guess.Properties, descriptionNames, notionapi.PropertyConfigTypeRichText); description != nil {
+		props.Description = description.Name
+	}
+	if slug := findProperty(guess.Properties, slugNames, notionapi.PropertyConfigTypeRichText); slug != nil {
+		props.Slug = slug.Name
+	}
+	if publishDate := findProperty(guess.Properties, publishDateNames, notionapi.PropertyConfigTypeDate); publishDate != nil {
+		props.PublishDate = publishDate.Name
+	}
+	return guess
+}
+
+// findProperty 返回类型为 types 之一且名称与 names 匹配的属性，names 为空时返回第一个类型匹配的属性
+func findProperty(properties []schemaProperty, names []string, types ...notionapi.PropertyConfigType) *schemaProperty {
+	candidates := make([]*schemaProperty, 0, len(properties))
+	for i := range properties {
+		for _, t := range types {
+			if properties[i].Type == t {
+				candidates = append(candidates, &properties[i])
+			}
+		}
+	}
+	if len(names) == 0 {
+		if len(candidates) > 0 {
+			return candidates[0]
+		}
+		return nil
+	}
+	if i := matchName(candidateNames(candidates), names); i >= 0 {
+		return candidates[i]
+	}
+	return nil
+}
+
+func candidateNames(properties []*schemaProperty) []string {
+	names := make([]string, len(properties))
+	for i, p := range properties {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// matchName 返回 values 中与 names 匹配的第一个值的序号，先找完全相同的，再找包含的，没有时返回 -1
+func matchName(values, names []string) int {
+	if i := exactMatch(values, names); i >= 0 {
+		return i
+	}
+	for _, name := range names {
+		for i, value := range values {
+			if strings.Contains(normalizeName(value), normalizeName(name)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func normalizeName(name string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(name))
+}
+
+// guessStatus 根据选项名称推测各个状态的取值，每个选项最多用于一个状态
+//
+// 名称无法判断时，Status 类型属性的 To-do 分组的第一个选项作为草稿，Complete 分组的第一个选项作为已发布。
+func guessStatus(property *schemaProperty) config.StatusConfig {
+	roles := make([]string, len(statusRoleNames))
+	used := make(map[string]bool)
+	assign := func(match func(values, names []string) int) {
+		for role, names := range statusRoleNames {
+			if roles[role] != "" {
+				continue
+			}
+			var available []string
+			for _, option := range property.Options {
+				if !used[option] {
+					available = append(available, option)
+				}
+			}
+			if i := match(available, names); i >= 0 {
+				roles[role] = available[i]
+				used[available[i]] = true
+			}
+		}
+	}
+	// 先为所有状态找名称完全相同的选项，避免 Unpublished 这样的选项被当作 Published
+	assign(exactMatch)
+	assign(matchName)
+
+	fallback := func(role int, group string) {
+		if roles[role] == "" {
+			for _, option := range property.Groups[group] {
+				if !used[option] {
+					roles[role] = option
+					used[option] = true
+					return
+				}
+			}
+		}
+	}
+	fallback(0, "To-do")
+	fallback(2, "Complete")
+
+	return config.StatusConfig{
+		Draft:     roles[0],
+		Ready:     roles[1],
+		Published: roles[2],
+		ToDelete:  roles[3],
+		Deleted:   roles[4],
+	}
+}
+
+// exactMatch 返回 values 中与 names 之一完全相同的第一个值的序号，没有时返回 -1
+func exactMatch(values, names []string) int {
+	for _, name := range names {
+		for i, value := range values {
+			if normalizeName(value) == normalizeName(name) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+var categorySlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// categorySlug 将分类名转换为目录名，汉字转换为拼音，保留字母和数字，例如 "Go 语言" 转换为 "go-yu-yan"
+func categorySlug(name string) string {
+	args := pinyin.NewArgs()
+	var b strings.Builder
+	for _, r := range name {
+		if pys := pinyin.SinglePinyin(r, args); len(pys) > 0 {
+			b.WriteString(" " + pys[0] + " ")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	slug := categorySlugPattern.ReplaceAllString(strings.ToLower(b.String()), "-")
+	return strings.Trim(slug, "-")
+}
+
+// printSchema 列出数据库的属性和推测的映射
+func printSchema(w io.Writer, guess *schemaGuess) {
+	fmt.Fprintf(w, "数据库: %s\n属性:\n", guess.Database)
+	for _, p := range guess.Properties {
+		fmt.Fprintf(w, "  %s（%s）", p.Name, p.Type)
+		if len(p.Options) > 0 {
+			fmt.Fprintf(w, ": %s", strings.Join(p.Options, "、"))
+		}
+		fmt.Fprintln(w)
+	}
+
+	props := guess.Config.Notion.Properties
+	fmt.Fprintln(w, "推测的映射:")
+	for _, field := range []struct{ name, value string }{
+		{"标题", props.Title},
+		{"状态", props.Status},
+		{"标签", props.Tags},
+		{"分类", props.Categories},
+	} {
+		value := field.value
+		if value == "" {
+			value = "未找到"
+		}
+		fmt.Fprintf(w, "  %s: %s\n", field.name, value)
+	}
+}
+
+// writeInitConfig 输出带注释的 YAML 配置，没有推测出的字段输出为注释
+func writeInitConfig(w io.Writer, guess *schemaGuess) {
+	c := guess.Config
+	fmt.Fprintf(w, "# notion2md 配置，由 notion2md init 根据数据库「%s」生成\n", guess.Database)
+	fmt.Fprintln(w, "# 属性和状态是根据名称推测的，请核对后再同步，完整的配置项见 README")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "databaseID: %s\n", yamlString(c.DatabaseID))
+	fmt.Fprintln(w, "# 目标：hugo、jekyll、hexo、zola、astro 或 html")
+	fmt.Fprintln(w, "target: hugo")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "content:")
+	fmt.Fprintln(w, "  folder: content/posts")
+	fmt.Fprintln(w, "  # Hugo 的文章模板，只用于 hugo 目标")
+	fmt.Fprintln(w, "  archetype: archetypes/default.md")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "notion:")
+	fmt.Fprintln(w, "  # 文章字段对应的 Notion 属性，数据库中的属性有：")
+	for _, p := range guess.Properties {
+		fmt.Fprintf(w, "  #   %s（%s）\n", p.Name, p.Type)
+	}
+	fmt.Fprintln(w, "  properties:")
+	props := c.Notion.Properties
+	writeField(w, "    ", "title", props.Title, "没有找到标题属性")
+	writeField(w, "    ", "status", props.Status, "没有找到状态属性")
+	writeField(w, "    ", "tags", props.Tags, "没有找到标签属性，多选属性才能作为标签")
+	writeField(w, "    ", "categories", props.Categories, "没有找到分类属性，单选或多选属性才能作为分类")
+	writeField(w, "    ", "description", props.Description, "可选，文本属性")
+	writeField(w, "    ", "slug", props.Slug, "可选，文本属性")
+	writeField(w, "    ", "publishDate", props.PublishDate, "可选，日期属性，用于计划发布")
+	fmt.Fprintln(w)
+
+	for _, p := range guess.Properties {
+		if p.Name == props.Status && len(p.Options) > 0 {
+			fmt.Fprintf(w, "  # 状态属性「%s」的取值有：%s\n", p.Name, strings.Join(p.Options, "、"))
+		}
+	}
+	fmt.Fprintln(w, "  status:")
+	status := c.Notion.Status
+	writeField(w, "    ", "draft", status.Draft, "草稿")
+	writeField(w, "    ", "ready", status.Ready, "待发布，同步时会转换这些文章")
+	writeField(w, "    ", "published", status.Published, "同步后文章会被设为该状态")
+	writeField(w, "    ", "toDelete", status.ToDelete, "可选，待删除，同步时会删除已生成的文章")
+	writeField(w, "    ", "deleted", status.Deleted, "可选，删除文章后设为该状态")
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "  # 分类到目录名的映射，目录名由拼音生成，可以改为更短的英文")
+	fmt.Fprintln(w, "  # 没有映射的分类下的文章会被跳过")
+	if len(guess.CategoryMap) == 0 {
+		fmt.Fprintln(w, "  categoryMap: {}")
+		return
+	}
+	fmt.Fprintln(w, "  categoryMap:")
+	names := make([]string, 0, len(guess.CategoryMap))
+	for name := range guess.CategoryMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "    %s: %s\n", yamlString(name), yamlString(guess.CategoryMap[name]))
+	}
+}
+
+// writeField 输出一个字段，值为空时输出为注释
+func writeField(w io.Writer, indent, key, value, comment string) {
+	if value == "" {
+		fmt.Fprintf(w, "%s# %s: \"\"  # %s\n", indent, key, comment)
+		return
+	}
+	fmt.Fprintf(w, "%s%s: %s\n", indent, key, yamlString(value))
+}
+
+// yamlString 返回双引号字符串，JSON 字符串也是合法的 YAML
+func yamlString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func plainText(text []notionapi.RichText) string {
+	var parts []string
+	for _, t := range text {
+		parts = append(parts, t.PlainText)
+	}
+	return strings.Join(parts, "")
+}
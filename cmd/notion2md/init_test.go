@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/fixture"
+)
+
+func TestInitConfig(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.SetDatabase("1429989fe8ac4effbc8f57f56486db54", `{
+		"title": [{"type": "text", "text": {"content": "博客"}, "plain_text": "博客"}],
+		"properties": {
+			"标题": {"id": "title", "type": "title", "title": {}},
+			"状态": {"id": "s", "type": "status", "status": {
+				"options": [
+					{"id": "1", "name": "未开始"},
+					{"id": "2", "name": "待发布"},
+					{"id": "3", "name": "已发布"},
+					{"id": "4", "name": "待删除"},
+					{"id": "5", "name": "已删除"}
+				],
+				"groups": [
+					{"id": "g1", "name": "To-do", "option_ids": ["1"]},
+					{"id": "g2", "name": "In progress", "option_ids": ["2", "4"]},
+					{"id": "g3", "name": "Complete", "option_ids": ["3", "5"]}
+				]
+			}},
+			"标签": {"id": "t", "type": "multi_select", "multi_select": {"options": [{"name": "go"}]}},
+			"分类": {"id": "c", "type": "select", "select": {"options": [{"name": "技术"}, {"name": "Go 语言"}, {"name": "🎉"}]}},
+			"摘要": {"id": "d", "type": "rich_text", "rich_text": {}},
+			"发布日期": {"id": "p", "type": "date", "date": {}}
+		}
+	}`)
+
+	output := filepath.Join(t.TempDir(), "notion2md.yaml")
+	options, err := parseInitFlags([]string{"-database", "https://www.notion.so/acme/1429989fe8ac4effbc8f57f56486db54?v=1", "-output", output})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newTestClient(server, &config.Config{RateLimit: 1000})
+	if err := initConfig(context.Background(), client, options, io.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.Load(output, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DatabaseID != "1429989fe8ac4effbc8f57f56486db54" {
+		t.Errorf("databaseID = %q", c.DatabaseID)
+	}
+	props := c.Notion.Properties
+	if props.Title != "标题" || props.Status != "状态" || props.Tags != "标签" || props.Categories != "分类" ||
+		props.Description != "摘要" || props.PublishDate != "发布日期" || props.Slug != "" {
+		t.Errorf("properties = %+v", props)
+	}
+	want := config.StatusConfig{Draft: "未开始", Ready: "待发布", Published: "已发布", ToDelete: "待删除", Deleted: "已删除"}
+	if c.Notion.Status != want {
+		t.Errorf("status = %+v，期望 %+v", c.Notion.Status, want)
+	}
+	for name, slug := range map[string]string{"技术": "ji-shu", "Go 语言": "go-yu-yan", "🎉": "category-3"} {
+		if c.Notion.CategoryMap[name] != slug {
+			t.Errorf("categoryMap[%q] = %q，期望 %q", name, c.Notion.CategoryMap[name], slug)
+		}
+	}
+
+	// 没有找到的字段输出为注释
+	data, _ := os.ReadFile(output)
+	if !strings.Contains(string(data), `# slug: ""`) {
+		t.Errorf("没有输出 slug 的注释:\n%s", data)
+	}
+
+	// 不覆盖已有的文件
+	if err := initConfig(context.Background(), client, options, io.Discard); err == nil {
+		t.Error("文件已存在时应返回错误")
+	}
+}
+
+func TestGuessStatus(t *testing.T) {
+	status := guessStatus(&schemaProperty{
+		Options: []string{"Not started", "In review", "Unpublished", "Ready to publish", "Shipped"},
+		Groups: map[string][]string{
+			"To-do":    {"Not started"},
+			"Complete": {"Shipped", "Unpublished"},
+		},
+	})
+	// Unpublished 不会被当作 Published，Complete 分组中剩下的 Shipped 作为已发布
+	want := config.StatusConfig{Draft: "Not started", Ready: "Ready to publish", Published: "Shipped", Deleted: "Unpublished"}
+	if status != want {
+		t.Errorf("status = %+v，期望 %+v", status, want)
+	}
+}
+
+func TestParseInitFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-database", "not-an-id"},
+		{"-database", "1429989fe8ac4effbc8f57f56486db54", "-output", "notion2md.json"},
+		{"-database", "1429989fe8ac4effbc8f57f56486db54", "extra"},
+	} {
+		if _, err := parseInitFlags(args); err == nil {
+			t.Errorf("parseInitFlags(%q) 应返回错误", args)
+		}
+	}
+}
@@ -3,79 +3,329 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
 	"time"
 
+	"notion2md/pkg/config"
 	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/astro"
+	"notion2md/pkg/converter/hexo"
+	"notion2md/pkg/converter/html"
 	"notion2md/pkg/converter/hugo"
+	"notion2md/pkg/converter/jekyll"
 	"notion2md/pkg/converter/media"
 	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/zola"
+	"notion2md/pkg/fixture"
 
 	"github.com/briandowns/spinner"
 	"github.com/jomei/notionapi"
 	"github.com/schollz/progressbar/v3"
 )
 
-var (
-	configFile string
-	envFile    string
-)
+func main() {
+	// 收到 SIGINT/SIGTERM 时取消 ctx，中断正在进行的 Notion 和 S3 请求
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
 
-func init() {
-	// 优先查找工作目录下的 notion.config.json
-	if _, err := os.Stat("notion.config.json"); err == nil {
-		configFile = "notion.config.json"
+func runSync(ctx context.Context, args []string) error {
+	flags := newFlagSet("sync", "sync [参数]", `同步每个来源：转换待发布的文章并把状态更新为已发布，把待删除的文章标记为已删除，
+发布日期未到的文章保持待发布状态。没有指定命令时执行 sync。`)
+	addDryRunFlag(flags, "只预览会发生的变化，不写入文件、不上传媒体也不更新状态")
+	report := addReportFlag(flags)
+	if err := parseArgs(flags, args); err != nil {
+		return configError(err)
 	}
-
-	flag.StringVar(&configFile, "config", configFile, "配置文件路径")
-	flag.StringVar(&envFile, "env", ".env", "环境变量文件路径")
+	if err := extraArgs(flags); err != nil {
+		return configError(err)
+	}
+	return syncAll(ctx, syncOptions{DryRun: dryRun}, *report)
 }
 
-func main() {
-	flag.Parse()
+func runExport(ctx context.Context, args []string) error {
+	flags := newFlagSet("export", "export [参数]", `转换每个来源中待发布和已发布的文章（配置了 notion.query.filter 时使用该条件），
+不更新 Notion 中的状态，也不处理待删除的文章。媒体文件照常保存，可以用 -content.folder 导出到其他目录。`)
+	report := addReportFlag(flags)
+	if err := parseArgs(flags, args); err != nil {
+		return configError(err)
+	}
+	if err := extraArgs(flags); err != nil {
+		return configError(err)
+	}
+	return syncAll(ctx, syncOptions{Export: true}, *report)
+}
 
-	// 加载配置
-	config, err := loadConfig(configFile)
+// syncAll 依次同步每个来源并输出汇总，有来源或文章失败时返回错误
+//
+// report 不为空时把运行结果写入该 JSON 文件，有来源失败时也会写入。
+func syncAll(ctx context.Context, options syncOptions, report string) error {
+	c, client, err := setup()
 	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+		return err
 	}
 
-	// 检查必要的配置
-	if config.DatabaseID == "" {
-		log.Fatal("未设置 Notion 数据库 ID")
+	status := &runStatus{Started: time.Now()}
+	options.Sources = c.SourceConfigs()
+	for _, source := range options.Sources {
+		// 失败的来源记录在汇总中，不影响其他来源
+		summary, _ := syncSource(ctx, client, source, options)
+		status.Sources = append(status.Sources, summary)
+		if ctx.Err() != nil {
+			break
+		}
 	}
+	status.Finished = time.Now()
 
-	// 初始化 Notion 客户端
+	printSummaries(status.Sources)
+	if report != "" {
+		if err := writeReport(report, status); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return summariesError(status.Sources)
+}
+
+// summariesError 在有来源或文章失败时返回错误
+func summariesError(summaries []*summary) error {
+	var sources, pages int
+	for _, s := range summaries {
+		if s.Err != nil {
+			sources++
+		}
+		pages += s.Failed
+	}
+	if sources == 0 && pages == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d 个来源失败，%d 篇文章失败", sources, pages)
+}
+
+// setup 加载并检查配置，创建 Notion 客户端，失败时返回的错误的退出码为 2
+func setup() (*config.Config, *notionapi.Client, error) {
+	c, err := loadConfig(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newClient(c)
+	if err != nil {
+		return nil, nil, configError(fmt.Errorf("初始化 Notion 客户端失败: %w", err))
+	}
+	return c, client, nil
+}
+
+// newClient 创建 Notion 客户端，所有来源共享同一个限速器
+func newClient(config *config.Config) (*notionapi.Client, error) {
 	token := os.Getenv("NOTION_SECRET")
-	if token == "" {
-		log.Fatal("未设置 NOTION_SECRET 环境变量")
+	if token == "" && replayDir == "" {
+		return nil, errors.New("未设置 NOTION_SECRET 环境变量")
+	}
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return notionapi.NewClient(notionapi.Token(token), notionapi.WithHTTPClient(&http.Client{
+		Transport: transport,
+	})), nil
+}
+
+// newTransport 创建 Notion 客户端的传输层
+//
+// 回放时直接从录制目录读取响应；否则请求经过限速和重试，录制时保存每个响应。
+func newTransport(config *config.Config) (http.RoundTripper, error) {
+	if replayDir != "" {
+		return fixture.NewReplayer(replayDir)
+	}
+
+	base := http.DefaultTransport
+	if recordDir != "" {
+		recorder, err := fixture.NewRecorder(base, recordDir)
+		if err != nil {
+			return nil, err
+		}
+		base = recorder
+	}
+	// 每次重试都重新等待限速器
+	return newRetryTransport(newRateLimitedTransport(base, config.RateLimit)), nil
+}
+
+// summary 是单个来源的同步结果
+type summary struct {
+	Source    string `json:"source"`
+	Found     int    `json:"found"`
+	Converted int    `json:"converted"`
+	Deleted   int    `json:"deleted"`
+	Scheduled int    `json:"scheduled"`
+	Skipped   int    `json:"skipped"`
+	Failed    int    `json:"failed"`
+	Err       error  `json:"-"`
+
+	// Pages 是每篇文章的处理结果
+	Pages []*pageResult `json:"pages"`
+
+	// NextScheduled 是计划发布的文章中最早的发布日期，没有计划发布的文章时为零值
+	NextScheduled time.Time `json:"-"`
+}
+
+// add 记录一篇文章的处理结果并计数
+func (s *summary) add(page *pageResult) {
+	switch page.Action {
+	case actionCreated, actionUpdated, actionUnchanged:
+		s.Converted++
+	case actionScheduled:
+		s.Scheduled++
+	case actionDeleted:
+		s.Deleted++
+	case actionSkipped:
+		s.Skipped++
+	case actionFailed:
+		s.Failed++
+	}
+	s.Pages = append(s.Pages, page)
+}
+
+// MarshalJSON 把 Err 输出为 error 字段
+func (s *summary) MarshalJSON() ([]byte, error) {
+	type plain summary
+	var errMessage string
+	if s.Err != nil {
+		errMessage = s.Err.Error()
+	}
+	return json.Marshal(struct {
+		*plain
+		Error string `json:"error,omitempty"`
+	}{(*plain)(s), errMessage})
+}
+
+// sourceName 返回来源名称，未设置时使用数据库 ID
+func sourceName(config *config.Config) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	return config.DatabaseID
+}
+
+// syncOptions 控制一次同步的范围和方式
+type syncOptions struct {
+	// Since 不为零值时只同步 Since 之后编辑过的文章
+	Since time.Time
+
+	// DryRun 为 true 时只输出会发生的变化，不写入文件、不上传媒体也不更新状态
+	DryRun bool
+
+	// Export 为 true 时查询待发布和已发布的文章，只转换不更新状态，待删除的文章跳过
+	Export bool
+
+	// Sources 为所有来源，用于解析指向其他来源文章的链接，为空时只解析当前来源
+	Sources []*config.Config
+}
+
+// syncSource 查询一个来源的数据库并转换其中的文章
+func syncSource(ctx context.Context, client *notionapi.Client, config *config.Config, options syncOptions) (*summary, error) {
+	result := &summary{Source: sourceName(config)}
+	fail := func(err error) (*summary, error) {
+		result.Err = err
+		return result, err
+	}
+
+	var preview converter.Writer
+	if options.DryRun {
+		preview = &diffWriter{w: os.Stdout}
+	}
+	p, err := newPipeline(ctx, client, config, options.Sources, preview)
+	if err != nil {
+		return fail(err)
+	}
+	p.export = options.Export
+
+	// 查询数据库，进度只在终端中显示，预览结果输出到标准输出时也不显示
+	progress := showProgress() && !options.DryRun
+	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+	s.Prefix = fmt.Sprintf("查询 Notion 数据库 [%s] ", result.Source)
+	if progress {
+		s.Start()
+	}
+	pages, err := queryDatabase(ctx, client, config, options)
+	s.Stop()
+	if err != nil {
+		return fail(fmt.Errorf("查询数据库失败: %w", notionError(err)))
+	}
+	result.Found = len(pages)
+	slog.Info("查询完成", "source", result.Source, "pages", len(pages))
+
+	// 处理每个页面
+	bar := progressbar.DefaultSilent(int64(len(pages)))
+	if progress {
+		bar = progressbar.Default(int64(len(pages)), "转换进度")
+	}
+	for _, page := range pages {
+		// 收到退出信号后不再处理剩余的文章
+		if ctx.Err() != nil {
+			return fail(ctx.Err())
+		}
+
+		bar.Describe(fmt.Sprintf("处理: %s", getPageTitle(page)))
+		p.syncPage(ctx, page, result)
+		bar.Add(1)
+	}
+
+	return result, nil
+}
+
+// pipeline 是一个来源的转换流程，包含该来源的转换器和元数据处理器
+type pipeline struct {
+	client        *notionapi.Client
+	config        *config.Config
+	conv          converter.Converter
+	metaProcessor *notion.MetadataProcessor
+
+	// writer 记录每篇文章创建或修改的文件
+	writer *changeWriter
+
+	// remover 删除待删除文章的文件，预览时只报告
+	remover fileRemover
+
+	// preview 不为 nil 时只预览，记录会保存的媒体且不更新状态
+	preview *previewMediaHandler
+
+	// export 为 true 时只转换文章，不更新状态
+	export bool
+}
+
+// newPipeline 检查来源的配置并创建转换流程，sources 为所有来源，用于解析文章之间的链接
+//
+// preview 不为 nil 时转换结果写入 preview，媒体文件不下载也不上传，页面中保留原始链接，
+// 页面状态也不会更新。
+func newPipeline(ctx context.Context, client *notionapi.Client, config *config.Config, sources []*config.Config, preview converter.Writer) (*pipeline, error) {
+	// 完整的检查在加载配置时进行，这里只防止查询空的数据库 ID
+	if config.DatabaseID == "" {
+		return nil, fmt.Errorf("未设置 Notion 数据库 ID")
 	}
-	client := notionapi.NewClient(notionapi.Token(token))
 
 	// 初始化媒体处理器
+	var previewMedia *previewMediaHandler
 	var mediaHandler converter.MediaHandler
-	switch config.Storage.Type {
-	case "local":
-		mediaHandler = media.NewLocalHandler(
-			config.Storage.Local.Path,
-			config.Storage.Local.URLPrefix,
-		)
-	case "s3":
+	if preview != nil {
+		previewMedia = &previewMediaHandler{storage: config.Storage}
+		mediaHandler = previewMedia
+	} else {
 		var err error
-		mediaHandler, err = media.NewS3Handler(
-			config.Storage.S3.Bucket,
-			config.Storage.S3.Region,
-			config.Storage.S3.PathPrefix,
-			config.Storage.S3.URLPrefix,
-		)
-		if err != nil {
-			log.Fatalf("初始化 S3 处理器失败: %v", err)
+		if mediaHandler, err = newMediaHandler(ctx, config); err != nil {
+			return nil, err
 		}
-	default:
-		log.Fatalf("不支持的存储类型: %s", config.Storage.Type)
 	}
 
 	// 初始化块处理器
@@ -85,180 +335,444 @@ func main() {
 	metaProcessor := notion.NewMetadataProcessor(config)
 
 	// 初始化转换器
-	conv := hugo.New(blockProcessor, metaProcessor)
-	if err := conv.SetTemplate(config.Content.Archetype); err != nil {
-		log.Fatalf("设置模板失败: %v", err)
+	conv, err := newConverter(ctx, client, config, sources, blockProcessor, metaProcessor)
+	if err != nil {
+		return nil, fmt.Errorf("初始化转换器失败: %w", err)
+	}
+
+	// 块模板在转换器之后注册，覆盖目标的内置渲染器
+	for blockType, path := range config.Blocks.Templates {
+		if err := blockProcessor.RegisterTemplate(notionapi.BlockType(blockType), path); err != nil {
+			return nil, fmt.Errorf("加载块模板失败 [%s]: %w", blockType, err)
+		}
+	}
+
+	if err := conv.SetTemplate(converterTemplate(config)); err != nil {
+		return nil, fmt.Errorf("设置模板失败: %w", err)
 	}
 	if err := conv.SetOutput(config.Content.Folder); err != nil {
-		log.Fatalf("设置输出目录失败: %v", err)
+		return nil, fmt.Errorf("设置输出目录失败: %w", err)
 	}
+	writer := &changeWriter{next: converter.DiskWriter{}}
+	var remover fileRemover = diskRemover{}
+	if preview != nil {
+		writer.next = preview
+		// 预览时只报告会删除的文件
+		remover = nopRemover{}
+		if r, ok := preview.(fileRemover); ok {
+			remover = r
+		}
+	}
+	conv.SetWriter(writer)
+
+	return &pipeline{
+		client:        client,
+		config:        config,
+		conv:          conv,
+		metaProcessor: metaProcessor,
+		writer:        writer,
+		remover:       remover,
+		preview:       previewMedia,
+	}, nil
+}
 
-	// 查询数据库
-	s := spinner.New(spinner.CharSets[11], 100*time.Millisecond)
-	s.Prefix = "查询 Notion 数据库 "
-	s.Start()
-	pages, err := queryDatabase(client, config)
-	s.Stop()
+// syncPage 转换单篇文章并更新状态，结果记录在 result 中
+func (p *pipeline) syncPage(ctx context.Context, page notionapi.Page, result *summary) {
+	record := newPageResult(page)
+	logger := record.logger(sourceName(p.config))
+	defer result.add(record)
+	if p.preview != nil {
+		fmt.Printf("\n[%s] %s\n", sourceName(p.config), record.Title)
+		defer p.preview.report(os.Stdout)
+	}
+	logger.Debug("处理文章")
+	p.writer.reset()
+
+	if p.export {
+		p.exportPage(ctx, page, record, logger)
+		return
+	}
+
+	deleted, removed, err := p.processPage(ctx, page)
 	if err != nil {
-		log.Fatalf("查询数据库失败: %v", err)
+		failPage(record, logger, err)
+		return
+	}
+	if deleted {
+		record.Action = actionDeleted
+		record.Files = removed
+		logger.Info("已删除文章", "files", len(removed))
+		return
+	}
+	record.Files = p.writer.files
+
+	// 发布日期未到的文章保持待发布状态，日期过后的下一次运行会再次处理
+	if p.metaProcessor.Scheduled(page, time.Now()) {
+		publishDate := p.metaProcessor.PublishDate(page)
+		record.Action = actionScheduled
+		logger.Info("已计划发布", "publish_date", publishDate.Format(time.RFC3339))
+		if result.NextScheduled.IsZero() || publishDate.Before(result.NextScheduled) {
+			result.NextScheduled = publishDate
+		}
+		return
 	}
-	fmt.Printf("✓ 找到 %d 篇文章\n", len(pages))
 
-	// 处理每个页面
-	bar := progressbar.Default(int64(len(pages)), "转换进度")
-	for _, page := range pages {
-		title := getPageTitle(page)
-		bar.Describe(fmt.Sprintf("处理: %s", title))
-
-		// 处理页面，如果返回 nil 说明跳过了这篇文章
-		if err := processPage(client, conv, page, config); err != nil {
-			if err == ErrSkipPage {
-				log.Printf("⚠️ 跳过文章 [%s]: 未配置分类映射", title)
-				continue
-			}
-			log.Printf("❌ 处理页面失败 [%s]: %v", title, err)
+	// 只有成功处理的文章才更新状态
+	if err := p.setStatus(ctx, page, p.config.Notion.Status.Published); err != nil {
+		failPage(record, logger, fmt.Errorf("更新状态失败: %w", err))
+		return
+	}
+	record.Action = p.writer.action()
+	logger.Info("已发布文章", "action", record.Action)
+}
+
+// exportPage 转换单篇文章但不更新状态，待删除的文章跳过
+func (p *pipeline) exportPage(ctx context.Context, page notionapi.Page, record *pageResult, logger *slog.Logger) {
+	if status := notion.PageStatus(page, notion.StatusProperty(p.config)); status != "" && status == p.config.Notion.Status.ToDelete {
+		record.Action = actionSkipped
+		record.Reason = "状态为 " + status
+		logger.Info("跳过文章", "reason", record.Reason)
+		return
+	}
+
+	if err := p.convert(ctx, page); err != nil {
+		failPage(record, logger, err)
+		return
+	}
+	record.Files = p.writer.files
+	record.Action = p.writer.action()
+	logger.Info("已导出文章", "action", record.Action)
+}
+
+// failPage 记录文章处理失败的原因，未配置分类映射的文章记为跳过
+func failPage(record *pageResult, logger *slog.Logger, err error) {
+	if err == ErrSkipPage {
+		record.Action = actionSkipped
+		record.Reason = "未配置分类映射"
+		logger.Warn("跳过文章", "reason", record.Reason)
+		return
+	}
+	record.Action = actionFailed
+	record.Error = err.Error()
+	logger.Error("处理文章失败", "error", err)
+}
+
+// printSummaries 输出每个来源的同步结果
+func printSummaries(summaries []*summary) {
+	for _, s := range summaries {
+		if s.Err != nil {
+			slog.Error("来源同步失败", "source", s.Source, "error", s.Err)
 			continue
 		}
+		level := slog.LevelInfo
+		if s.Failed > 0 {
+			level = slog.LevelWarn
+		}
+		slog.Log(context.Background(), level, "来源同步完成", "source", s.Source,
+			"found", s.Found, "converted", s.Converted, "scheduled", s.Scheduled,
+			"deleted", s.Deleted, "skipped", s.Skipped, "failed", s.Failed)
+	}
+}
+
+// newMediaHandler 根据来源的存储配置创建媒体处理器
+func newMediaHandler(ctx context.Context, config *config.Config) (converter.MediaHandler, error) {
+	switch config.Storage.Type {
+	case "local":
+		return media.NewLocalHandler(
+			ctx,
+			config.Storage.Local.Path,
+			config.Storage.Local.URLPrefix,
+		), nil
+	case "s3":
+		handler, err := media.NewS3Handler(
+			ctx,
+			config.Storage.S3.Bucket,
+			config.Storage.S3.Region,
+			config.Storage.S3.PathPrefix,
+			config.Storage.S3.URLPrefix,
+			config.Storage.S3.Endpoint,
+			config.Storage.S3.ForcePathStyle,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("初始化 S3 处理器失败: %w", err)
+		}
+		return handler, nil
+	}
+	return nil, fmt.Errorf("不支持的存储类型: %s", config.Storage.Type)
+}
 
-		// 只有成功处理的文章才更新状态
-		if err := updateStatus(client, page, config.Notion.Status.Published); err != nil {
-			log.Printf("⚠️ 更新状态失败 [%s]: %v", title, err)
-		} else {
-			log.Printf("✓ 已完成: %s", title)
+// newConverter 根据配置的 target 创建对应静态站点生成器的转换器
+func newConverter(ctx context.Context, client *notionapi.Client, config *config.Config, sources []*config.Config, blockProcessor *notion.BlockProcessor, metaProcessor *notion.MetadataProcessor) (converter.Converter, error) {
+	switch config.Target {
+	case "", "hugo":
+		conv := hugo.New(blockProcessor, metaProcessor)
+		// ref 短代码只能用于 Hugo 风格的输出
+		if config.Flavor == "" || config.Flavor == notion.FlavorHugo {
+			blockProcessor.SetLinkResolver(hugo.NewLinkResolver(ctx, client, config, sources))
 		}
+		return conv, nil
+	case "jekyll":
+		return jekyll.New(blockProcessor, metaProcessor), nil
+	case "hexo":
+		return hexo.New(blockProcessor, metaProcessor), nil
+	case "zola":
+		return zola.New(blockProcessor, metaProcessor), nil
+	case "astro":
+		return astro.New(blockProcessor, metaProcessor), nil
+	case "html":
+		return html.New(html.NewBlockProcessor(blockProcessor), metaProcessor, config.HTML.Fragment), nil
+	}
+	return nil, fmt.Errorf("不支持的目标: %s", config.Target)
+}
 
-		bar.Add(1)
+// converterTemplate 返回目标使用的模板，Hugo 的 archetype 不会用于其他目标
+func converterTemplate(config *config.Config) string {
+	switch config.Target {
+	case "", "hugo":
+		return config.Content.Archetype
+	case "html":
+		return config.HTML.Template
 	}
+	return config.Content.Template
 }
 
 // 定义一个特殊的错误类型表示跳过文章
-var ErrSkipPage = fmt.Errorf("跳过文章")
-
-func queryDatabase(client *notionapi.Client, config *converter.Config) ([]notionapi.Page, error) {
-	// 查询待发布的文章
-	readyQuery := &notionapi.DatabaseQueryRequest{
-		Filter: &notionapi.PropertyFilter{
-			Property: "Status",
-			Status: &notionapi.StatusFilterCondition{
-				Equals: config.Notion.Status.Ready,
-			},
-		},
-		PageSize: 100,
+var ErrSkipPage = converter.ErrSkipPage
+
+// queryDatabase 按配置的过滤条件和排序查询数据库，并读取所有分页
+//
+// options.Since 不为零值时只查询该时间之后编辑过的页面，导出时查询待发布和已发布的页面。
+func queryDatabase(ctx context.Context, client *notionapi.Client, config *config.Config, options syncOptions) ([]notionapi.Page, error) {
+	newQuery := notion.NewQuery
+	if options.Export {
+		newQuery = notion.NewExportQuery
 	}
-
-	readyResp, err := client.Database.Query(context.Background(), notionapi.DatabaseID(config.DatabaseID), readyQuery)
+	query, err := newQuery(config, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("查询待发布文章失败: %w", err)
+		return nil, err
+	}
+	if !options.Since.IsZero() {
+		notion.EditedSince(query, options.Since)
 	}
 
-	// 查询待删除的文章
-	deleteQuery := &notionapi.DatabaseQueryRequest{
-		Filter: &notionapi.PropertyFilter{
-			Property: "Status",
-			Status: &notionapi.StatusFilterCondition{
-				Equals: config.Notion.Status.ToDelete,
-			},
-		},
-		PageSize: 100,
+	var pages []notionapi.Page
+	for {
+		resp, err := client.Database.Query(ctx, notionapi.DatabaseID(config.DatabaseID), query)
+		if err != nil {
+			return nil, fmt.Errorf("查询文章失败: %w", err)
+		}
+		pages = append(pages, resp.Results...)
+		if !resp.HasMore {
+			return pages, nil
+		}
+		query.StartCursor = resp.NextCursor
 	}
+}
 
-	deleteResp, err := client.Database.Query(context.Background(), notionapi.DatabaseID(config.DatabaseID), deleteQuery)
-	if err != nil {
-		return nil, fmt.Errorf("查询待删除文章失败: %w", err)
+// processPage 转换单篇文章
+//
+// 待删除的文章删除输出文件和本地媒体目录，并把状态更新为已删除，deleted 为 true，removed 为删除的文件。
+func (p *pipeline) processPage(ctx context.Context, page notionapi.Page) (deleted bool, removed []string, err error) {
+	// 检查状态
+	if status := notion.PageStatus(page, notion.StatusProperty(p.config)); status != "" && status == p.config.Notion.Status.ToDelete {
+		removed, err := p.removePage(page)
+		if err != nil {
+			return false, nil, fmt.Errorf("删除文件失败: %w", err)
+		}
+		if err := p.setStatus(ctx, page, p.config.Notion.Status.Deleted); err != nil {
+			return false, removed, fmt.Errorf("更新状态失败: %w", err)
+		}
+		return true, removed, nil
 	}
 
-	// 合并结果
-	return append(readyResp.Results, deleteResp.Results...), nil
+	// 处理正常文章
+	return false, nil, p.convert(ctx, page)
 }
 
-func processPage(client *notionapi.Client, conv converter.Converter, page notionapi.Page, config *converter.Config) error {
-	// 检查状态
-	if status, ok := page.Properties["Status"].(*notionapi.StatusProperty); ok {
-		if status.Status.Name == config.Notion.Status.ToDelete {
-			log.Printf("🗑 删除文章: %s", getPageTitle(page))
-			if err := updateStatus(client, page, config.Notion.Status.Deleted); err != nil {
-				return fmt.Errorf("更新状态失败: %w", err)
+// removePage 删除文章的输出文件和本地存储中该文章的媒体目录，返回删除（预览时为会删除）的文件
+//
+// 未配置分类映射的文章没有生成过文件，不删除任何文件。
+func (p *pipeline) removePage(page notionapi.Page) ([]string, error) {
+	locator, ok := p.conv.(converter.Locator)
+	if !ok {
+		return nil, nil
+	}
+	file, mediaDir, err := locator.Locate(page)
+	if err == ErrSkipPage {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	if _, err := os.Stat(file); err == nil {
+		files = append(files, file)
+	}
+	var dir string
+	if p.config.Storage.Type == "local" && mediaDir != "" {
+		dir = filepath.Join(p.config.Storage.Local.Path, mediaDir)
+		err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				// 文章没有保存过媒体时目录不存在
+				if path == dir && errors.Is(err, fs.ErrNotExist) {
+					return fs.SkipAll
+				}
+				return err
+			}
+			if !entry.IsDir() {
+				files = append(files, path)
 			}
 			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// 处理正常文章
-	blocks, err := getPageBlocks(client, page.ID)
+	for _, path := range files {
+		if err := p.remover.RemoveFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if dir != "" && p.preview == nil {
+		removeEmptyDirs(dir)
+		os.Remove(dir)
+	}
+	return files, nil
+}
+
+// fileRemover 删除文件
+type fileRemover interface {
+	RemoveFile(path string) error
+}
+
+// diskRemover 从文件系统中删除文件
+type diskRemover struct{}
+
+func (diskRemover) RemoveFile(path string) error {
+	return os.Remove(path)
+}
+
+// nopRemover 不删除文件，用于输出到标准输出等不会报告删除的预览
+type nopRemover struct{}
+
+func (nopRemover) RemoveFile(string) error {
+	return nil
+}
+
+// convert 读取页面内容并转换，未配置分类映射时返回 ErrSkipPage
+func (p *pipeline) convert(ctx context.Context, page notionapi.Page) error {
+	blocks, err := getPageBlocks(ctx, p.client, page.ID)
 	if err != nil {
 		return fmt.Errorf("获取页面内容失败: %w", err)
 	}
 
-	if err := conv.Convert(page, blocks); err != nil {
+	if err := p.conv.Convert(page, blocks); err != nil {
 		if err == ErrSkipPage {
 			return ErrSkipPage
 		}
 		return fmt.Errorf("转换内容失败: %w", err)
 	}
-
 	return nil
 }
 
-func getPageBlocks(client *notionapi.Client, pageID notionapi.ObjectID) ([]notionapi.Block, error) {
-	resp, err := client.Block.GetChildren(context.Background(), notionapi.BlockID(pageID), &notionapi.Pagination{
-		PageSize: 100,
-	})
+// setStatus 更新页面状态，预览时只输出会更新的状态
+func (p *pipeline) setStatus(ctx context.Context, page notionapi.Page, newStatus string) error {
+	if p.preview != nil {
+		fmt.Printf("  状态: %s -> %s\n", notion.PageStatus(page, notion.StatusProperty(p.config)), newStatus)
+		return nil
+	}
+	return updateStatus(ctx, p.client, page, p.config, newStatus)
+}
+
+// listChildren 读取块的所有直接子块，包括后续分页
+func listChildren(ctx context.Context, client *notionapi.Client, blockID notionapi.BlockID) ([]notionapi.Block, error) {
+	pagination := &notionapi.Pagination{PageSize: 100}
+	var children []notionapi.Block
+	for {
+		resp, err := client.Block.GetChildren(ctx, blockID, pagination)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, resp.Results...)
+		if !resp.HasMore {
+			return children, nil
+		}
+		pagination.StartCursor = notionapi.Cursor(resp.NextCursor)
+	}
+}
+
+func getPageBlocks(ctx context.Context, client *notionapi.Client, pageID notionapi.ObjectID) ([]notionapi.Block, error) {
+	children, err := listChildren(ctx, client, notionapi.BlockID(pageID))
 	if err != nil {
 		return nil, err
 	}
 
 	var blocks []notionapi.Block
-	for _, block := range resp.Results {
+	for _, block := range children {
 		// 递归获取子块
 		switch b := block.(type) {
 		case *notionapi.ParagraphBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.Paragraph.Children = children
 		case *notionapi.BulletedListItemBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.BulletedListItem.Children = children
 		case *notionapi.NumberedListItemBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.NumberedListItem.Children = children
 		case *notionapi.ToDoBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.ToDo.Children = children
 		case *notionapi.ToggleBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.Toggle.Children = children
 		case *notionapi.QuoteBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.Quote.Children = children
 		case *notionapi.CalloutBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.Callout.Children = children
 		case *notionapi.ColumnListBlock:
-			children, err := getPageBlocks(client, notionapi.ObjectID(b.ID))
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
 			if err != nil {
 				return nil, err
 			}
 			b.ColumnList.Children = children
+		case *notionapi.TableBlock:
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
+			if err != nil {
+				return nil, err
+			}
+			b.Table.Children = children
+		case *notionapi.ColumnBlock:
+			children, err := getPageBlocks(ctx, client, notionapi.ObjectID(b.ID))
+			if err != nil {
+				return nil, err
+			}
+			b.Column.Children = children
 		}
 		blocks = append(blocks, block)
 	}
@@ -266,39 +780,97 @@ func getPageBlocks(client *notionapi.Client, pageID notionapi.ObjectID) ([]notio
 	return blocks, nil
 }
 
-func updateStatus(client *notionapi.Client, page notionapi.Page, newStatus string) error {
-	props := notionapi.Properties{
-		"Status": notionapi.StatusProperty{
-			Status: notionapi.Status{
-				Name: newStatus,
+// updateStatus 更新页面的状态属性，没有 Status 或 Select 类型状态属性的页面（例如用复选框发布）不更新
+func updateStatus(ctx context.Context, client *notionapi.Client, page notionapi.Page, config *config.Config, newStatus string) error {
+	name := notion.StatusProperty(config)
+
+	var props notionapi.Properties
+	switch page.Properties[name].(type) {
+	case *notionapi.StatusProperty:
+		props = notionapi.Properties{
+			name: notionapi.StatusProperty{
+				Status: notionapi.Status{
+					Name: newStatus,
+				},
+			},
+		}
+	case *notionapi.SelectProperty:
+		props = notionapi.Properties{
+			name: notionapi.SelectProperty{
+				Select: notionapi.Option{
+					Name: newStatus,
+				},
 			},
-		},
+		}
+	default:
+		return nil
 	}
 
-	_, err := client.Page.Update(context.Background(), notionapi.PageID(page.ID), &notionapi.PageUpdateRequest{
+	_, err := client.Page.Update(ctx, notionapi.PageID(page.ID), &notionapi.PageUpdateRequest{
 		Properties: props,
 	})
 	return err
 }
 
-func loadConfig(path string) (*converter.Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+// loadEnvFile 加载 -env 指定的文件，未指定时加载工作目录下的 .env，不存在不算错误
+func loadEnvFile() error {
+	if envFile == "" {
+		err := config.LoadDotenv(".env")
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
 	}
+	return config.LoadDotenv(envFile)
+}
+
+// defaultConfigFiles 为未指定配置文件时依次查找的文件
+var defaultConfigFiles = []string{"notion.config.json", "notion2md.yaml", "notion2md.yml", "notion2md.toml"}
 
-	var config converter.Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		fmt.Println("config", string(data))
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+// defaultConfigFile 返回 NOTION2MD_CONFIG 或工作目录下第一个存在的默认配置文件，都没有时返回空字符串
+func defaultConfigFile() string {
+	if path := os.Getenv("NOTION2MD_CONFIG"); path != "" {
+		return path
 	}
+	for _, path := range defaultConfigFiles {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
 
-	return &config, nil
+// loadConfig 加载 .env 后依次应用默认值、配置文件、环境变量和参数
+//
+// validate 为 true 时检查配置，配置有问题时一次返回所有问题。返回的错误的退出码为 2。
+func loadConfig(validate bool) (*config.Config, error) {
+	// .env 中的变量（例如 NOTION_SECRET）在解析配置之前加载
+	if err := loadEnvFile(); err != nil {
+		return nil, configError(fmt.Errorf("加载环境变量文件失败: %w", err))
+	}
+	path := configFile
+	if path == "" {
+		path = defaultConfigFile()
+	}
+	c, err := config.Load(path, os.LookupEnv, configFlags)
+	if err != nil {
+		return nil, configError(fmt.Errorf("加载配置失败: %w", err))
+	}
+	if strict {
+		c.Blocks.Strict = true
+	}
+	if validate {
+		if err := c.Validate(); err != nil {
+			return nil, configError(err)
+		}
+	}
+	return c, nil
 }
 
+// getPageTitle 返回页面标题，每个数据库页面有且只有一个标题属性
 func getPageTitle(page notionapi.Page) string {
-	if title, ok := page.Properties["Name"].(*notionapi.TitleProperty); ok {
-		if len(title.Title) > 0 {
+	for _, prop := range page.Properties {
+		if title, ok := prop.(*notionapi.TitleProperty); ok && len(title.Title) > 0 {
 			return title.Title[0].PlainText
 		}
 	}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+// pageOptions 是 page 子命令的参数
+type pageOptions struct {
+	PageID       string
+	UpdateStatus bool
+	Stdout       bool
+}
+
+func runPage(ctx context.Context, args []string) error {
+	options, err := parsePageFlags(args)
+	if err != nil {
+		return configError(err)
+	}
+	c, client, err := setup()
+	if err != nil {
+		return err
+	}
+	if err := convertPage(ctx, client, c, options); err != nil {
+		return fmt.Errorf("转换页面失败: %w", err)
+	}
+	return nil
+}
+
+func parsePageFlags(args []string) (*pageOptions, error) {
+	options := &pageOptions{}
+	flags := newFlagSet("page", "page [参数] <页面 ID 或链接>", `转换单篇文章并写入文件，不论页面处于什么状态。默认不更新页面状态，
+页面必须属于某个来源的数据库，使用该来源的配置转换。`)
+	flags.BoolVar(&options.UpdateStatus, "update-status", false, "转换后把页面状态更新为已发布")
+	flags.BoolVar(&options.Stdout, "stdout", false, "把转换结果输出到标准输出，不写入文件也不保存媒体")
+	addDryRunFlag(flags, "只预览会发生的变化，不写入文件、不上传媒体也不更新状态")
+	if err := parseArgs(flags, args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != 1 {
+		return nil, fmt.Errorf("需要一个页面 ID 或 Notion 页面链接")
+	}
+
+	pageID, err := notion.ParsePageID(flags.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	options.PageID = pageID
+	if options.Stdout && options.UpdateStatus {
+		return nil, fmt.Errorf("-stdout 不能与 -update-status 同时使用")
+	}
+	return options, nil
+}
+
+// convertPage 转换单篇文章并写入文件，不论页面处于什么状态
+func convertPage(ctx context.Context, client *notionapi.Client, config *config.Config, options *pageOptions) error {
+	page, err := client.Page.Get(ctx, notionapi.PageID(options.PageID))
+	if err != nil {
+		return fmt.Errorf("获取页面失败: %w", err)
+	}
+	source := sourceForPage(config, page)
+	if source == nil {
+		return fmt.Errorf("页面不属于任何来源的数据库: %s", page.Parent.DatabaseID)
+	}
+
+	var preview converter.Writer
+	stdout := &stdoutWriter{w: os.Stdout}
+	switch {
+	case options.Stdout:
+		preview = stdout
+	case dryRun:
+		preview = &diffWriter{w: os.Stdout}
+	}
+	p, err := newPipeline(ctx, client, source, config.SourceConfigs(), preview)
+	if err != nil {
+		return err
+	}
+	// 输出到标准输出时不保存媒体，保留 Notion 中的原始链接
+	if options.Stdout {
+		p.preview.keepLinks = true
+		// 只输出文章本身，不输出 zola 的 _index.md 等准备文件
+		if locator, ok := p.conv.(converter.Locator); ok {
+			file, _, err := locator.Locate(*page)
+			if err != nil && err != ErrSkipPage {
+				return err
+			}
+			stdout.path = file
+		}
+	}
+
+	blocks, err := getPageBlocks(ctx, client, page.ID)
+	if err != nil {
+		return fmt.Errorf("获取页面内容失败: %w", err)
+	}
+	record := newPageResult(*page)
+	logger := record.logger(sourceName(source))
+	if dryRun && !options.Stdout {
+		fmt.Printf("[%s] %s\n", sourceName(source), record.Title)
+		defer p.preview.report(os.Stdout)
+	}
+	if err := p.conv.Convert(*page, blocks); err != nil {
+		if err == ErrSkipPage {
+			return fmt.Errorf("未配置分类映射")
+		}
+		return fmt.Errorf("转换内容失败: %w", err)
+	}
+
+	if !options.UpdateStatus {
+		logger.Info("已转换文章", "action", p.writer.action())
+		return nil
+	}
+	if p.metaProcessor.Scheduled(*page, time.Now()) {
+		logger.Info("已计划发布，状态不变", "publish_date", p.metaProcessor.PublishDate(*page).Format(time.RFC3339))
+		return nil
+	}
+	if err := p.setStatus(ctx, *page, source.Notion.Status.Published); err != nil {
+		return fmt.Errorf("更新状态失败: %w", err)
+	}
+	logger.Info("已发布文章", "action", p.writer.action())
+	return nil
+}
+
+// stdoutWriter 把转换结果写到标准输出而不是文件
+type stdoutWriter struct {
+	w io.Writer
+	// path 非空时只输出该文件，其他文件被忽略
+	path string
+}
+
+func (s *stdoutWriter) WriteFile(path string, data []byte) error {
+	if s.path != "" && path != s.path {
+		return nil
+	}
+	_, err := s.w.Write(data)
+	return err
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/fixture"
+)
+
+func TestParsePageFlags(t *testing.T) {
+	options, err := parsePageFlags([]string{"-stdout", "https://www.notion.so/acme/Hello-1429989fe8ac4effbc8f57f56486db54?pvs=4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if options.PageID != "1429989fe8ac4effbc8f57f56486db54" || !options.Stdout || options.UpdateStatus {
+		t.Errorf("参数解析错误: %+v", options)
+	}
+
+	for _, args := range [][]string{
+		{},
+		{"a", "b"},
+		{"not-an-id"},
+		{"-stdout", "-update-status", "1429989fe8ac4effbc8f57f56486db54"},
+	} {
+		if _, err := parsePageFlags(args); err == nil {
+			t.Errorf("parsePageFlags(%q) 应返回错误", args)
+		}
+	}
+}
+
+func TestConvertPageStdout(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"))
+
+	config := testConfig(t)
+	config.Target = "zola"
+
+	// 把标准输出重定向到文件
+	out, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	stdout := os.Stdout
+	os.Stdout = out
+	err = convertPage(context.Background(), newTestClient(server, config), config, &pageOptions{PageID: "page-ready", Stdout: true})
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 只输出文章，不输出 section 的 _index.md
+	if got := string(data); strings.Count(got, "+++\n") != 2 || !strings.Contains(got, `title = "你好"`) || !strings.Contains(got, "第一段") {
+		t.Errorf("标准输出的内容为:\n%s", got)
+	}
+	if _, err := os.Stat(config.Content.Folder); !os.IsNotExist(err) {
+		t.Errorf("-stdout 不应写入内容目录: %v", err)
+	}
+}
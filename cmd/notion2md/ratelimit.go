@@ -0,0 +1,32 @@
+package main
+
+import (
+	"net/http"
+
+	"notion2md/pkg/config"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedTransport 在发送请求前等待限速器，所有来源共享同一个限速器
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func newRateLimitedTransport(base http.RoundTripper, requestsPerSecond float64) *rateLimitedTransport {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = config.DefaultRateLimit
+	}
+	return &rateLimitedTransport{
+		base:    base,
+		limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+// 文章的处理结果
+const (
+	actionCreated   = "created"   // 写入了新文件
+	actionUpdated   = "updated"   // 修改了已有的文件
+	actionUnchanged = "unchanged" // 输出与已有的文件相同
+	actionScheduled = "scheduled" // 已写入，发布日期未到，状态不变
+	actionDeleted   = "deleted"   // 待删除的文章，状态已更新为已删除
+	actionSkipped   = "skipped"   // 跳过，例如未配置分类映射
+	actionFailed    = "failed"    // 处理失败，原因见 Error
+)
+
+// pageResult 是单篇文章的处理结果
+type pageResult struct {
+	ID     string   `json:"id"`
+	Title  string   `json:"title"`
+	Slug   string   `json:"slug"`
+	Action string   `json:"action"`
+	Files  []string `json:"files,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// newPageResult 创建页面的处理结果，slug 与输出文件名相同
+func newPageResult(page notionapi.Page) *pageResult {
+	title := getPageTitle(page)
+	return &pageResult{
+		ID:    string(page.ID),
+		Title: title,
+		Slug:  site.Slugify(page, map[string]interface{}{"title": title}),
+	}
+}
+
+// logger 返回带有页面 ID、标题和 slug 的日志记录器
+func (r *pageResult) logger(source string) *slog.Logger {
+	return slog.With("source", source, "page_id", r.ID, "title", r.Title, "slug", r.Slug)
+}
+
+// changeWriter 在写入前与已有的文件比较，记录当前文章创建或修改了哪些文件
+type changeWriter struct {
+	next    converter.Writer
+	files   []string
+	changed map[string]bool // 值为 true 表示修改了已有的文件
+}
+
+func (w *changeWriter) WriteFile(path string, data []byte) error {
+	old, readErr := os.ReadFile(path)
+	if err := w.next.WriteFile(path, data); err != nil {
+		return err
+	}
+	if w.changed == nil {
+		w.changed = make(map[string]bool)
+	}
+	w.files = append(w.files, path)
+	if readErr == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	w.changed[path] = readErr == nil
+	return nil
+}
+
+// reset 清空记录，每篇文章开始处理前调用
+func (w *changeWriter) reset() {
+	w.files = nil
+	w.changed = nil
+}
+
+// action 根据写入的文件返回文章的处理结果：有新文件时为 created，有修改时为 updated，否则为 unchanged
+func (w *changeWriter) action() string {
+	action := actionUnchanged
+	for _, updated := range w.changed {
+		if !updated {
+			return actionCreated
+		}
+		action = actionUpdated
+	}
+	return action
+}
+
+// addReportFlag 注册 -report，返回运行报告的路径
+func addReportFlag(fs *flag.FlagSet) *string {
+	return fs.String("report", "", "把每个来源和每篇文章的处理结果写入该 JSON 文件，供后续的自动化流程读取")
+}
+
+// writeReport 把运行结果写入 JSON 文件
+func writeReport(path string, status *runStatus) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入运行报告失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter"
+)
+
+func TestChangeWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a", "post.md")
+	w := &changeWriter{next: converter.DiskWriter{}}
+
+	for _, tt := range []struct {
+		content string
+		want    string
+	}{
+		{"第一版", actionCreated},
+		{"第一版", actionUnchanged},
+		{"第二版", actionUpdated},
+	} {
+		w.reset()
+		if err := w.WriteFile(path, []byte(tt.content)); err != nil {
+			t.Fatal(err)
+		}
+		if got := w.action(); got != tt.want {
+			t.Errorf("写入 %q 的结果为 %q，期望 %q", tt.content, got, tt.want)
+		}
+		if len(w.files) != 1 {
+			t.Errorf("记录的文件: %v", w.files)
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "run.json")
+	page := &pageResult{ID: "page-1", Title: "你好", Slug: "ni-hao", Action: actionFailed, Error: "上传失败"}
+	status := &runStatus{
+		Started:  time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		Finished: time.Date(2024, 5, 1, 8, 1, 0, 0, time.UTC),
+		Sources: []*summary{
+			{Source: "blog", Found: 1, Failed: 1, Pages: []*pageResult{page}},
+			{Source: "notes", Err: errors.New("查询数据库失败")},
+		},
+	}
+	if err := writeReport(path, status); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Sources []struct {
+			Source string       `json:"source"`
+			Failed int          `json:"failed"`
+			Error  string       `json:"error"`
+			Pages  []pageResult `json:"pages"`
+		} `json:"sources"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Sources) != 2 || len(got.Sources[0].Pages) != 1 || !reflect.DeepEqual(got.Sources[0].Pages[0], *page) {
+		t.Fatalf("报告内容不一致:\n%s", data)
+	}
+	if got.Sources[1].Error != "查询数据库失败" {
+		t.Errorf("失败的来源没有 error 字段:\n%s", data)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 请求失败后最多重试的次数
+const maxRetries = 4
+
+// retryTransport 在 Notion 返回 429 或 5xx 时重试请求
+//
+// 等待时间优先使用 Retry-After，没有时按 backoff 指数增长。notionapi 自带的重试
+// 会复用已读完的请求体，因此由这里负责重试并在每次重试前重新生成请求体。
+type retryTransport struct {
+	base    http.RoundTripper
+	backoff time.Duration
+}
+
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{base: base, backoff: 500 * time.Millisecond}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil || !retryable(resp.StatusCode) || attempt == maxRetries {
+			return resp, err
+		}
+		// 没有 GetBody 的请求体无法重新发送
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp, t.backoff<<attempt)
+		resp.Body.Close()
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter 返回响应要求的等待时间，没有 Retry-After 时返回 fallback
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
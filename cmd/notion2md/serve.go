@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+// 同步整个数据库的任务使用空的页面 ID
+const fullSync = ""
+
+// 队列中最多等待的任务数
+const maxQueuedJobs = 64
+
+// signatureHeader 是 HMAC-SHA256 签名所在的请求头，格式为 sha256=<hex>
+const signatureHeader = "X-Notion2md-Signature"
+
+// serveOptions 是 serve 子命令的参数
+type serveOptions struct {
+	Addr   string
+	Secret string
+}
+
+func runServe(ctx context.Context, args []string) error {
+	options, err := parseServeFlags(args)
+	if err != nil {
+		return configError(err)
+	}
+	c, client, err := setup()
+	if err != nil {
+		return err
+	}
+	if err := serve(ctx, client, c, options); err != nil {
+		return fmt.Errorf("服务失败: %w", err)
+	}
+	return nil
+}
+
+func parseServeFlags(args []string) (*serveOptions, error) {
+	options := &serveOptions{}
+	flags := newFlagSet("serve", "serve [参数]", `启动 HTTP 服务，按顺序执行同步请求，直到收到退出信号。
+POST /sync 同步所有来源，POST /sync/<页面 ID> 只同步该页面，请求需要带上
+Authorization: Bearer <密钥> 或 X-Notion2md-Signature 签名；GET /healthz 检查服务是否运行，
+GET /status 返回最近一次同步的结果。`)
+	flags.StringVar(&options.Addr, "addr", ":8080", "HTTP 监听地址")
+	flags.StringVar(&options.Secret, "secret", os.Getenv("NOTION2MD_WEBHOOK_SECRET"), "共享密钥，默认读取 NOTION2MD_WEBHOOK_SECRET 环境变量")
+	if err := parseArgs(flags, args); err != nil {
+		return nil, err
+	}
+	if err := extraArgs(flags); err != nil {
+		return nil, err
+	}
+	if options.Secret == "" {
+		return nil, fmt.Errorf("未设置共享密钥，使用 -secret 或 NOTION2MD_WEBHOOK_SECRET")
+	}
+	return options, nil
+}
+
+// runStatus 是一次同步任务的结果
+type runStatus struct {
+	PageID   string     `json:"pageID,omitempty"`
+	Started  time.Time  `json:"started"`
+	Finished time.Time  `json:"finished"`
+	Sources  []*summary `json:"sources"`
+}
+
+// syncServer 接收同步请求并按顺序执行，等待中的相同任务只保留一个
+type syncServer struct {
+	client *notionapi.Client
+	config *config.Config
+	secret []byte
+
+	mu      sync.Mutex
+	pending map[string]bool
+	running string
+	busy    bool
+	lastRun *runStatus
+	jobs    chan string
+}
+
+func newSyncServer(client *notionapi.Client, config *config.Config, secret string) *syncServer {
+	return &syncServer{
+		client:  client,
+		config:  config,
+		secret:  []byte(secret),
+		pending: make(map[string]bool),
+		jobs:    make(chan string, maxQueuedJobs),
+	}
+}
+
+// serve 启动 HTTP 服务，直到 ctx 被取消
+func serve(ctx context.Context, client *notionapi.Client, config *config.Config, options *serveOptions) error {
+	s := newSyncServer(client, config, options.Secret)
+	server := &http.Server{
+		Addr:              options.Addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go s.work(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("开始监听", "addr", options.Addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("已停止服务")
+	return nil
+}
+
+func (s *syncServer) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /sync", s.handleSync)
+	mux.HandleFunc("POST /sync/{pageID}", s.handleSync)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /status", s.handleStatus)
+	return mux
+}
+
+func (s *syncServer) handleSync(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "读取请求失败", http.StatusBadRequest)
+		return
+	}
+	if !s.authorized(r, body) {
+		http.Error(w, "未授权", http.StatusUnauthorized)
+		return
+	}
+
+	pageID := r.PathValue("pageID")
+	if pageID != "" {
+		if pageID, err = notion.ParsePageID(pageID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	queued, err := s.enqueue(pageID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"pageID": pageID,
+		"queued": queued,
+	})
+}
+
+func (s *syncServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok\n"))
+}
+
+func (s *syncServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := map[string]interface{}{
+		"busy":    s.busy,
+		"queued":  len(s.pending),
+		"lastRun": s.lastRun,
+	}
+	if s.busy {
+		status["running"] = s.running
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// authorized 校验 Bearer 共享密钥或请求体的 HMAC-SHA256 签名
+func (s *syncServer) authorized(r *http.Request, body []byte) bool {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return subtle.ConstantTimeCompare([]byte(token), s.secret) == 1
+	}
+	if signature, ok := strings.CutPrefix(r.Header.Get(signatureHeader), "sha256="); ok {
+		got, err := hex.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, s.secret)
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	return false
+}
+
+// enqueue 把任务加入队列，已在等待的相同任务不会重复加入
+func (s *syncServer) enqueue(pageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 等待中的整库同步会包含该页面
+	if s.pending[pageID] || (pageID != fullSync && s.pending[fullSync]) {
+		return false, nil
+	}
+	select {
+	case s.jobs <- pageID:
+		s.pending[pageID] = true
+		return true, nil
+	default:
+		return false, fmt.Errorf("同步队列已满")
+	}
+}
+
+// work 依次执行队列中的任务，直到 ctx 被取消
+func (s *syncServer) work(ctx context.Context) {
+	for {
+		var pageID string
+		select {
+		case <-ctx.Done():
+			return
+		case pageID = <-s.jobs:
+		}
+
+		s.mu.Lock()
+		delete(s.pending, pageID)
+		s.running = pageID
+		s.busy = true
+		s.mu.Unlock()
+
+		status := s.run(ctx, pageID)
+
+		s.mu.Lock()
+		s.busy = false
+		s.lastRun = status
+		s.mu.Unlock()
+	}
+}
+
+func (s *syncServer) run(ctx context.Context, pageID string) *runStatus {
+	status := &runStatus{PageID: pageID, Started: time.Now()}
+	if pageID == fullSync {
+		sources := s.config.SourceConfigs()
+		for _, source := range sources {
+			summary, _ := syncSource(ctx, s.client, source, syncOptions{Sources: sources})
+			status.Sources = append(status.Sources, summary)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	} else {
+		summary, _ := syncPageByID(ctx, s.client, s.config, pageID)
+		status.Sources = append(status.Sources, summary)
+	}
+	status.Finished = time.Now()
+	printSummaries(status.Sources)
+	return status
+}
+
+// syncPageByID 获取单篇文章并用它所属来源的转换流程同步
+//
+// 只同步处于待发布或待删除状态的文章，草稿不会因为同步请求被发布。
+func syncPageByID(ctx context.Context, client *notionapi.Client, config *config.Config, pageID string) (*summary, error) {
+	result := &summary{Source: pageID}
+	fail := func(err error) (*summary, error) {
+		result.Err = err
+		return result, err
+	}
+
+	page, err := client.Page.Get(ctx, notionapi.PageID(pageID))
+	if err != nil {
+		return fail(fmt.Errorf("获取页面失败: %w", err))
+	}
+	source := sourceForPage(config, page)
+	if source == nil {
+		return fail(fmt.Errorf("页面不属于任何来源的数据库: %s", page.Parent.DatabaseID))
+	}
+	result.Source = sourceName(source)
+	result.Found = 1
+
+	// 状态属性是 Status 或 Select 时检查状态，其他发布方式（例如复选框）直接同步
+	if status := notion.PageStatus(*page, notion.StatusProperty(source)); status != "" &&
+		status != source.Notion.Status.Ready && status != source.Notion.Status.ToDelete {
+		record := newPageResult(*page)
+		record.Action = actionSkipped
+		record.Reason = "状态为 " + status
+		record.logger(result.Source).Info("跳过文章", "reason", record.Reason)
+		result.add(record)
+		return result, nil
+	}
+
+	p, err := newPipeline(ctx, client, source, config.SourceConfigs(), nil)
+	if err != nil {
+		return fail(err)
+	}
+	p.syncPage(ctx, *page, result)
+	return result, nil
+}
+
+// sourceForPage 返回页面所属数据库对应的来源
+func sourceForPage(config *config.Config, page *notionapi.Page) *config.Config {
+	for _, source := range config.SourceConfigs() {
+		if notion.NormalizeID(source.DatabaseID) == notion.NormalizeID(string(page.Parent.DatabaseID)) {
+			return source
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+)
+
+func TestSyncServerAuth(t *testing.T) {
+	s := newSyncServer(nil, &config.Config{}, "secret")
+	handler := s.routes()
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		body   string
+		want   int
+	}{
+		{"no credentials", "", "", "", http.StatusUnauthorized},
+		{"wrong token", "Authorization", "Bearer nope", "", http.StatusUnauthorized},
+		{"token", "Authorization", "Bearer secret", "", http.StatusAccepted},
+		{"signature", signatureHeader, sign(`{"a":1}`), `{"a":1}`, http.StatusAccepted},
+		{"signature of other body", signatureHeader, sign(`{"a":1}`), `{"a":2}`, http.StatusUnauthorized},
+		{"malformed signature", signatureHeader, "sha256=zz", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sync/1429989fe8ac4effbc8f57f56486db54", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("状态码 %d，期望 %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncServerPageID(t *testing.T) {
+	s := newSyncServer(nil, &config.Config{}, "secret")
+	handler := s.routes()
+
+	for _, tt := range []struct {
+		pageID string
+		want   int
+	}{
+		{"1429989f-e8ac-4eff-bc8f-57f56486db54", http.StatusAccepted},
+		{"Hello-World-1429989fe8ac4effbc8f57f56486db54", http.StatusAccepted},
+		{"not-a-page", http.StatusBadRequest},
+		{"1429989fe8ac", http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/sync/"+tt.pageID, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s 的状态码 %d，期望 %d", tt.pageID, rec.Code, tt.want)
+			continue
+		}
+		if tt.want != http.StatusAccepted {
+			continue
+		}
+		var got struct {
+			PageID string `json:"pageID"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.PageID != "1429989fe8ac4effbc8f57f56486db54" {
+			t.Errorf("%s 解析为 %q", tt.pageID, got.PageID)
+		}
+	}
+}
+
+func TestSyncServerDeduplicates(t *testing.T) {
+	s := newSyncServer(nil, &config.Config{}, "secret")
+
+	for _, tt := range []struct {
+		pageID string
+		want   bool
+	}{
+		{"a", true},
+		{"a", false},
+		{"b", true},
+		{fullSync, true},
+		{fullSync, false},
+		// 等待中的整库同步已包含单篇文章
+		{"c", false},
+	} {
+		queued, err := s.enqueue(tt.pageID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if queued != tt.want {
+			t.Errorf("enqueue(%q) = %v，期望 %v", tt.pageID, queued, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status struct {
+		Queued int `json:"queued"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Queued != 3 {
+		t.Errorf("等待中的任务 %d，期望 3", status.Queued)
+	}
+}
+
+func TestSyncServerQueueFull(t *testing.T) {
+	s := newSyncServer(nil, &config.Config{}, "secret")
+	for i := 0; i < maxQueuedJobs; i++ {
+		if _, err := s.enqueue(strings.Repeat("x", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.enqueue("overflow"); err == nil {
+		t.Error("队列已满时应返回错误")
+	}
+}
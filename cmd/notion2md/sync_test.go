@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/fixture"
+
+	"github.com/jomei/notionapi"
+)
+
+const testDatabase = "db"
+
+// testPage 返回 Notion API 格式的页面，extra 是附加的属性
+func testPage(id, title, status, category, extra string) string {
+	return fmt.Sprintf(`{
+		"id": %q,
+		"created_time": "2024-01-01T00:00:00Z",
+		"last_edited_time": "2024-01-02T00:00:00Z",
+		"created_by": {"object": "user", "id": "u"},
+		"properties": {
+			"Name": {"id": "title", "type": "title", "title": [{"type": "text", "text": {"content": %q}, "plain_text": %q}]},
+			"Status": {"id": "s", "type": "status", "status": {"name": %q}},
+			"Category": {"id": "c", "type": "select", "select": {"name": %q}}%s
+		}
+	}`, id, title, title, status, category, extra)
+}
+
+func testParagraph(id, text string) string {
+	return fmt.Sprintf(`{"object": "block", "id": %q, "type": "paragraph", "paragraph": {"rich_text": [
+		{"type": "text", "text": {"content": %q}, "annotations": {}, "plain_text": %q}
+	]}}`, id, text, text)
+}
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	dir := t.TempDir()
+	var config config.Config
+	config.DatabaseID = testDatabase
+	config.RateLimit = 1000
+	config.Content.Folder = filepath.Join(dir, "content")
+	config.Content.Archetype = filepath.Join("..", "..", "archetypes", "post.md")
+	config.Storage.Type = "local"
+	config.Storage.Local.Path = filepath.Join(dir, "static")
+	config.Storage.Local.URLPrefix = "/images"
+	config.Notion.CategoryMap = map[string]string{"技术": "tech"}
+	config.Notion.Status.Ready = "Ready"
+	config.Notion.Status.Published = "Published"
+	config.Notion.Status.ToDelete = "To Delete"
+	config.Notion.Status.Deleted = "Deleted"
+	config.Notion.Properties.PublishDate = "Publish Date"
+	return &config
+}
+
+// newTestClient 创建连接假服务的客户端，请求经过与正式运行相同的重试和限速
+func newTestClient(server *fixture.Server, config *config.Config) *notionapi.Client {
+	retry := newRetryTransport(newRateLimitedTransport(server.Transport(), config.RateLimit))
+	retry.backoff = time.Millisecond
+	return notionapi.NewClient("test-token", notionapi.WithHTTPClient(&http.Client{Transport: retry}))
+}
+
+func pageStatusName(server *fixture.Server, id string) string {
+	properties, _ := server.Page(id)["properties"].(map[string]interface{})
+	status, _ := properties["Status"].(map[string]interface{})
+	value, _ := status["status"].(map[string]interface{})
+	name, _ := value["name"].(string)
+	return name
+}
+
+func TestSyncSource(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	// 每页一条，查询和子块都需要读取多页
+	server.PageSize = 1
+
+	future := time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddPage(testDatabase, testPage("page-delete", "旧文章", "To Delete", "技术", ""))
+	server.AddPage(testDatabase, testPage("page-scheduled", "未来", "Ready", "技术",
+		fmt.Sprintf(`, "Publish Date": {"id": "p", "type": "date", "date": {"start": %q}}`, future)))
+	server.AddPage(testDatabase, testPage("page-unmapped", "杂谈", "Ready", "其他", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"), testParagraph("b2", "第二段"))
+
+	config := testConfig(t)
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := summary{Source: testDatabase, Found: 4, Converted: 1, Deleted: 1, Scheduled: 1, Skipped: 1}
+	got := *result
+	got.NextScheduled = time.Time{}
+	got.Pages = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("汇总不一致\ngot:  %+v\nwant: %+v", got, want)
+	}
+	for _, page := range result.Pages {
+		wantAction := map[string]string{
+			"page-ready":     actionCreated,
+			"page-delete":    actionDeleted,
+			"page-scheduled": actionScheduled,
+			"page-unmapped":  actionSkipped,
+		}[page.ID]
+		if page.Action != wantAction {
+			t.Errorf("%s 的结果为 %q，期望 %q", page.ID, page.Action, wantAction)
+		}
+	}
+	if ready := result.Pages[0]; ready.Slug != "ni-hao" || len(ready.Files) != 1 {
+		t.Errorf("页面结果不完整: %+v", ready)
+	}
+
+	content, err := os.ReadFile(filepath.Join(config.Content.Folder, "tech", "ni-hao.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"title: 你好", "第一段", "第二段"} {
+		if !strings.Contains(string(content), text) {
+			t.Errorf("输出缺少 %q:\n%s", text, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(config.Content.Folder, "tech", "wei-lai.md")); err != nil {
+		t.Errorf("计划发布的文章也应写入: %v", err)
+	}
+
+	for id, status := range map[string]string{
+		"page-ready":     "Published",
+		"page-delete":    "Deleted",
+		"page-scheduled": "Ready",
+		"page-unmapped":  "Ready",
+	} {
+		if got := pageStatusName(server, id); got != status {
+			t.Errorf("%s 状态为 %q，期望 %q", id, got, status)
+		}
+	}
+
+	// 再次转换相同的内容时文件不变（假服务不按状态过滤）
+	result, err = syncSource(context.Background(), newTestClient(server, config), config, syncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action := result.Pages[0].Action; action != actionUnchanged {
+		t.Errorf("再次同步的结果为 %q，期望 %q", action, actionUnchanged)
+	}
+}
+
+func TestSyncSourceExport(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddPage(testDatabase, testPage("page-published", "旧文", "Published", "技术", ""))
+	server.AddPage(testDatabase, testPage("page-delete", "旧文章", "To Delete", "技术", ""))
+
+	config := testConfig(t)
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{Export: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Converted != 2 || result.Skipped != 1 || result.Failed != 0 {
+		t.Errorf("汇总不一致: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(config.Content.Folder, "tech", "jiu-wen.md")); err != nil {
+		t.Errorf("已发布的文章也应导出: %v", err)
+	}
+
+	// 导出不更新任何状态
+	for id, status := range map[string]string{
+		"page-ready":     "Ready",
+		"page-published": "Published",
+		"page-delete":    "To Delete",
+	} {
+		if got := pageStatusName(server, id); got != status {
+			t.Errorf("%s 状态为 %q，期望 %q", id, got, status)
+		}
+	}
+}
+
+func TestSyncSourceRetries(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"))
+
+	// 查询和状态更新都先失败再成功，重试时必须重新发送请求体
+	server.FailNext(http.MethodPost, http.StatusTooManyRequests, http.StatusBadGateway)
+	server.FailNext(http.MethodPatch, http.StatusTooManyRequests)
+
+	config := testConfig(t)
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Converted != 1 || result.Failed != 0 {
+		t.Errorf("重试后应成功: %+v", result)
+	}
+	if got := pageStatusName(server, "page-ready"); got != "Published" {
+		t.Errorf("状态为 %q，期望 Published", got)
+	}
+
+	var patches []fixture.Request
+	for _, req := range server.Requests() {
+		if req.Method == http.MethodPatch {
+			patches = append(patches, req)
+		}
+	}
+	if len(patches) != 2 {
+		t.Fatalf("PATCH 请求 %d 次，期望 2 次", len(patches))
+	}
+	for _, patch := range patches {
+		if !strings.Contains(patch.Body, "Published") {
+			t.Errorf("重试的请求体丢失: %q", patch.Body)
+		}
+	}
+}
+
+func TestSyncSourceDryRun(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddPage(testDatabase, testPage("page-delete", "旧文章", "To Delete", "技术", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"))
+
+	config := testConfig(t)
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Converted != 1 || result.Deleted != 1 {
+		t.Errorf("预览的汇总不一致: %+v", result)
+	}
+
+	if _, err := os.Stat(config.Content.Folder); err == nil {
+		t.Error("预览不应写入文件")
+	}
+	for _, req := range server.Requests() {
+		if req.Method == http.MethodPatch {
+			t.Errorf("预览不应更新状态: %s", req.Path)
+		}
+	}
+}
+
+func TestSyncSourceDryRunMedia(t *testing.T) {
+	image := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer image.Close()
+
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"), fmt.Sprintf(
+		`{"object": "block", "id": "b2", "type": "image", "image": {"type": "file", "file": {"url": %q}}}`,
+		image.URL+"/a.png?X-Amz-Signature=1"))
+
+	config := testConfig(t)
+	if _, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已有文件中的链接是保存后的链接，预览时链接相同，文章没有变化
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action := result.Pages[0].Action; action != actionUnchanged {
+		t.Errorf("预览的结果为 %q，期望 %q", action, actionUnchanged)
+	}
+}
+
+func TestSyncSourceDelete(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-delete", "旧文章", "To Delete", "技术", ""))
+
+	config := testConfig(t)
+	post := filepath.Join(config.Content.Folder, "tech", "jiu-wen-zhang.md")
+	media := filepath.Join(config.Storage.Local.Path, "tech", "jiu-wen-zhang", "a.png")
+	other := filepath.Join(config.Storage.Local.Path, "tech", "ni-hao", "b.png")
+	for _, path := range []string{post, media, other} {
+		writeTestFile(t, path, "x")
+	}
+
+	// 预览只列出会删除的文件
+	result, err := syncSource(context.Background(), newTestClient(server, config), config, syncOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{post, media}
+	if got := result.Pages[0].Files; !reflect.DeepEqual(got, want) {
+		t.Errorf("预览会删除的文件为 %q，期望 %q", got, want)
+	}
+	if _, err := os.Stat(post); err != nil {
+		t.Error("预览不应删除文件")
+	}
+
+	result, err = syncSource(context.Background(), newTestClient(server, config), config, syncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page := result.Pages[0]; page.Action != actionDeleted || !reflect.DeepEqual(page.Files, want) {
+		t.Errorf("删除结果不一致: %+v", page)
+	}
+	for _, path := range []string{post, filepath.Dir(media)} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("%s 没有删除", path)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("其他文章的媒体被删除: %v", err)
+	}
+	if got := pageStatusName(server, "page-delete"); got != "Deleted" {
+		t.Errorf("状态为 %q，期望 Deleted", got)
+	}
+}
+
+func TestSyncSourceReplay(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage(testDatabase, testPage("page-ready", "你好", "Ready", "技术", ""))
+	server.AddBlocks("page-ready", testParagraph("b1", "第一段"))
+
+	fixtures := t.TempDir()
+	recorder, err := fixture.NewRecorder(server.Transport(), fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := testConfig(t)
+	client := notionapi.NewClient("test-token", notionapi.WithHTTPClient(&http.Client{Transport: recorder}))
+	if _, err := syncSource(context.Background(), client, recorded, syncOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	// 回放时不访问假服务，输出与录制时相同
+	replayer, err := fixture.NewReplayer(fixtures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := testConfig(t)
+	client = notionapi.NewClient("test-token", notionapi.WithHTTPClient(&http.Client{Transport: replayer}))
+	result, err := syncSource(context.Background(), client, replayed, syncOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Converted != 1 || result.Failed != 0 {
+		t.Errorf("回放的汇总不一致: %+v", result)
+	}
+
+	want, err := os.ReadFile(filepath.Join(recorded.Content.Folder, "tech", "ni-hao.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(replayed.Content.Folder, "tech", "ni-hao.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("回放输出不一致\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
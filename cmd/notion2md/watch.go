@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"time"
+
+	"notion2md/pkg/config"
+
+	"github.com/jomei/notionapi"
+)
+
+// watchOptions 是 watch 子命令的参数
+type watchOptions struct {
+	Interval time.Duration
+	PostSync string
+}
+
+func runWatch(ctx context.Context, args []string) error {
+	options, err := parseWatchFlags(args)
+	if err != nil {
+		return configError(err)
+	}
+	c, client, err := setup()
+	if err != nil {
+		return err
+	}
+	if err := watch(ctx, client, c, options); err != nil {
+		return fmt.Errorf("监听失败: %w", err)
+	}
+	return nil
+}
+
+func parseWatchFlags(args []string) (*watchOptions, error) {
+	options := &watchOptions{}
+	flags := newFlagSet("watch", "watch [参数]", `定期轮询每个来源，只同步上次轮询之后编辑过的文章，直到收到退出信号。
+第一次轮询同步所有文章，计划发布的文章在发布日期过后同步。`)
+	flags.DurationVar(&options.Interval, "interval", 5*time.Minute, "轮询数据库的间隔")
+	flags.StringVar(&options.PostSync, "post-sync", "", "有文章变化时执行的命令，例如 \"hugo --minify\"")
+	if err := parseArgs(flags, args); err != nil {
+		return nil, err
+	}
+	if err := extraArgs(flags); err != nil {
+		return nil, err
+	}
+	if options.Interval <= 0 {
+		return nil, fmt.Errorf("轮询间隔必须大于 0: %s", options.Interval)
+	}
+	return options, nil
+}
+
+// watch 定期轮询每个来源，只同步上次轮询之后编辑过的文章，直到 ctx 被取消
+//
+// 第一次轮询同步所有文章。计划发布的文章不会因为日期到达而被编辑，
+// 因此最早的发布日期过后会重新同步一次整个来源。
+func watch(ctx context.Context, client *notionapi.Client, config *config.Config, options *watchOptions) error {
+	sources := config.SourceConfigs()
+	lastPoll := make([]time.Time, len(sources))
+	nextScheduled := make([]time.Time, len(sources))
+
+	for {
+		var summaries []*summary
+		changed := false
+		for i, source := range sources {
+			start := time.Now()
+			since := lastPoll[i]
+			if !nextScheduled[i].IsZero() && !start.Before(nextScheduled[i]) {
+				since = time.Time{}
+			}
+
+			summary, err := syncSource(ctx, client, source, syncOptions{Since: since, Sources: sources})
+			if ctx.Err() != nil {
+				slog.Info("已停止监听")
+				return nil
+			}
+			summaries = append(summaries, summary)
+			// 失败的来源在下一次轮询时从上次成功的时间重新同步
+			if err != nil {
+				continue
+			}
+			lastPoll[i] = start
+			nextScheduled[i] = summary.NextScheduled
+			if summary.Converted > 0 || summary.Deleted > 0 {
+				changed = true
+			}
+		}
+		printSummaries(summaries)
+
+		if changed && options.PostSync != "" {
+			if err := runPostSync(ctx, options.PostSync); err != nil {
+				slog.Error("执行同步后命令失败", "command", options.PostSync, "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("已停止监听")
+			return nil
+		case <-time.After(options.Interval):
+		}
+	}
+}
+
+// runPostSync 通过 shell 执行同步后命令，输出直接写到标准输出和标准错误
+func runPostSync(ctx context.Context, command string) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -1,11 +1,6 @@
 #!/bin/sh
 set -e
 
-# 如果没有配置文件，使用默认配置
-if [ ! -f "notion.config.json" ]; then
-    echo "未找到 notion.config.json，使用默认配置"
-    exit 1
-fi
-
-# 运行转换器
-exec notion2md 
\ No newline at end of file
+# 没有 notion.config.json 时只使用环境变量（例如 DATABASE_ID、NOTION2MD_CONTENT_ARCHETYPE）和参数，
+# 缺少的配置会在启动时一次列出
+exec notion2md "$@"
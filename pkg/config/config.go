@@ -0,0 +1,248 @@
+// Package config 定义 notion2md 的配置文件格式、默认值和校验
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/jomei/notionapi"
+)
+
+// 输出的 Markdown 风格
+const (
+	// FlavorHugo 使用目标站点生成器的短代码和 HTML
+	FlavorHugo = "hugo"
+	// FlavorGFM 输出 GitHub Flavored Markdown，不使用短代码
+	FlavorGFM = "gfm"
+	// FlavorCommonMark 输出可移植的 CommonMark，不使用短代码和 HTML 布局
+	FlavorCommonMark = "commonmark"
+)
+
+// DefaultRateLimit 是 Notion API 平均每秒允许的请求数
+const DefaultRateLimit = 3
+
+type Config struct {
+	// Name 为来源名称，用于日志和汇总
+	Name       string `json:"name"`
+	DatabaseID string `json:"databaseID" env:"DATABASE_ID"`
+	// RateLimit 为所有来源共享的 Notion API 每秒请求数，默认为 3
+	RateLimit float64 `json:"rateLimit"`
+	// Sources 为多个数据库来源，每个来源的字段与顶层相同，未设置的字段使用顶层配置
+	Sources []Config `json:"sources"`
+	// Target 为目标：hugo（默认）、jekyll、hexo、zola、astro 或 html
+	Target string `json:"target"`
+	// Flavor 为输出的 Markdown 风格：hugo（默认，使用目标的短代码）、gfm 或 commonmark
+	Flavor  string        `json:"flavor"`
+	Content ContentConfig `json:"content"`
+	Storage StorageConfig `json:"storage"`
+	Notion  NotionConfig  `json:"notion"`
+	Blocks  BlocksConfig  `json:"blocks"`
+	Code    CodeConfig    `json:"code"`
+	HTML    HTMLConfig    `json:"html"`
+	Image   ImageConfig   `json:"image"`
+}
+
+type ContentConfig struct {
+	Folder string `json:"folder" env:"CONTENT_FOLDER"`
+	// Archetype 为 Hugo 的文章模板，只用于 hugo 目标
+	Archetype string `json:"archetype"`
+	// Template 为其他 Markdown 目标的可选文章模板，可使用 .FrontMatter 和 .Content
+	Template string `json:"template"`
+}
+
+type StorageConfig struct {
+	// Type 为媒体文件的存储方式：local（默认）或 s3
+	Type  string       `json:"type" env:"STORAGE_TYPE"`
+	Local LocalStorage `json:"local"`
+	S3    S3Storage    `json:"s3"`
+}
+
+type LocalStorage struct {
+	Path      string `json:"path" env:"IMAGES_FOLDER"`
+	URLPrefix string `json:"urlPrefix" env:"IMAGES_PREFIX"`
+}
+
+type S3Storage struct {
+	Bucket     string `json:"bucket" env:"S3_BUCKET"`
+	Region     string `json:"region" env:"S3_REGION"`
+	PathPrefix string `json:"pathPrefix" env:"S3_PATH_PREFIX"`
+	URLPrefix  string `json:"urlPrefix" env:"S3_URL_PREFIX"`
+	// Endpoint 为兼容 S3 的服务地址，例如 R2 或 MinIO，为空时使用 AWS 或 AWS_ENDPOINT_URL_S3
+	Endpoint string `json:"endpoint" env:"S3_ENDPOINT"`
+	// ForcePathStyle 为 true 时使用路径风格的地址（<endpoint>/<bucket>/<key>），MinIO 等服务需要
+	ForcePathStyle bool `json:"forcePathStyle"`
+}
+
+type NotionConfig struct {
+	Status      StatusConfig      `json:"status"`
+	CategoryMap map[string]string `json:"categoryMap"`
+	Query       QueryConfig       `json:"query"`
+	Properties  PropertiesConfig  `json:"properties"`
+}
+
+// StatusConfig 为状态属性各个取值的名称
+type StatusConfig struct {
+	Draft     string `json:"draft"`
+	Ready     string `json:"ready"`
+	Published string `json:"published"`
+	ToDelete  string `json:"toDelete"`
+	Deleted   string `json:"deleted"`
+}
+
+type QueryConfig struct {
+	// Filter 为 Notion API 格式的过滤条件，支持 and/or 组合，日期条件中可以使用 "today" 和 "now"
+	// 为空时查询状态为 ready 或 toDelete 的文章
+	Filter json.RawMessage `json:"filter"`
+	// Sorts 为 Notion API 格式的排序条件
+	Sorts []notionapi.SortObject `json:"sorts"`
+}
+
+// PropertiesConfig 为文章各个字段对应的 Notion 属性名
+type PropertiesConfig struct {
+	Title       string `json:"title"`
+	Categories  string `json:"categories"`
+	Tags        string `json:"tags"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	MetaTitle   string `json:"metaTitle"`
+	Slug        string `json:"slug"`
+	Toc         string `json:"toc"`
+	Comments    string `json:"comments"`
+	Weight      string `json:"weight"`
+	// PublishDate 为计划发布日期属性，日期未到的文章不会被标记为已发布
+	PublishDate string `json:"publishDate"`
+	// ExpiryDate 为过期日期属性，输出为 Hugo 的 expiryDate
+	ExpiryDate string `json:"expiryDate"`
+}
+
+type BlocksConfig struct {
+	// TableOfContents 目录块输出的标记，为空时使用目标站点生成器的默认标记，只用于 hugo 风格
+	TableOfContents string `json:"tableOfContents"`
+	// Breadcrumb 面包屑块输出的标记，为空时不输出
+	Breadcrumb string `json:"breadcrumb"`
+	// Unsupported 未支持块的回退方式：skip（默认）或 comment（输出 HTML 注释占位）
+	Unsupported string `json:"unsupported"`
+	// Strict 为 true 时遇到未支持的块会使页面转换失败
+	Strict bool `json:"strict"`
+	// Templates 将块类型映射到 Go text/template 文件，覆盖内置渲染
+	Templates map[string]string `json:"templates"`
+}
+
+type CodeConfig struct {
+	// MermaidShortcode 非空时 mermaid 代码块输出为该短代码，否则输出 mermaid 代码围栏
+	MermaidShortcode string `json:"mermaidShortcode"`
+	// Languages 将 Notion 的语言名映射为代码围栏语言，键不区分大小写
+	Languages map[string]string `json:"languages"`
+}
+
+type HTMLConfig struct {
+	// Fragment 为 true 时只输出 <article> 片段而不是完整页面
+	Fragment bool `json:"fragment"`
+	// Template 为可选的 html/template 文件，可以重新定义 page 或 article 模板
+	Template string `json:"template"`
+}
+
+type ImageConfig struct {
+	MaxWidth int      `json:"maxWidth"`
+	Quality  int      `json:"quality"`
+	Formats  []string `json:"formats"`
+}
+
+// UnmarshalJSON 同时接受旧版配置中的 max_width
+func (c *ImageConfig) UnmarshalJSON(data []byte) error {
+	type plain ImageConfig
+	var legacy struct {
+		plain
+		LegacyMaxWidth int `json:"max_width"`
+	}
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*c = ImageConfig(legacy.plain)
+	if c.MaxWidth == 0 {
+		c.MaxWidth = legacy.LegacyMaxWidth
+	}
+	return nil
+}
+
+// Load 依次应用默认值、配置文件、环境变量和命令行参数，不做校验
+//
+// path 为空时不读取配置文件，只用环境变量和命令行参数也能得到完整的配置。
+// 配置文件按扩展名解析为 JSON、YAML 或 TOML，也可以是带有 params.notion2md 部分的 Hugo 站点配置。
+// lookupEnv 通常为 os.LookupEnv，flags 可以为 nil。
+func Load(path string, lookupEnv func(string) (string, bool), flags *Flags) (*Config, error) {
+	var c Config
+	if path != "" {
+		if err := decodeFile(path, &c); err != nil {
+			return nil, err
+		}
+	}
+	if lookupEnv != nil {
+		if err := c.ApplyEnv(lookupEnv); err != nil {
+			return nil, err
+		}
+	}
+	if err := flags.Apply(&c); err != nil {
+		return nil, err
+	}
+	// 默认值只填充仍未设置的字段，效果与最先应用相同
+	c.SetDefaults()
+	return &c, nil
+}
+
+// SetDefaults 为未设置的字段填充默认值
+//
+// 默认值只写入顶层，来源中未设置的字段会在 SourceConfigs 中继承顶层的值。
+func (c *Config) SetDefaults() {
+	setDefault(&c.Target, "hugo")
+	setDefault(&c.Flavor, FlavorHugo)
+	if c.RateLimit == 0 {
+		c.RateLimit = DefaultRateLimit
+	}
+	setDefault(&c.Content.Folder, "content/posts")
+	setDefault(&c.Storage.Type, "local")
+	setDefault(&c.Storage.Local.Path, "static/images")
+	setDefault(&c.Storage.Local.URLPrefix, "/images")
+	setDefault(&c.Storage.S3.PathPrefix, "images")
+	setDefault(&c.Notion.Properties.Status, "Status")
+	setDefault(&c.Blocks.Unsupported, "skip")
+}
+
+func setDefault(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// SourceConfigs 返回每个来源合并顶层配置后的完整配置，没有配置来源时返回顶层配置本身
+//
+// 来源中的零值字段视为未设置，因此布尔选项只能在来源中打开，映射和列表整体替换顶层的值。
+func (c *Config) SourceConfigs() []*Config {
+	if len(c.Sources) == 0 {
+		return []*Config{c}
+	}
+
+	configs := make([]*Config, 0, len(c.Sources))
+	for _, source := range c.Sources {
+		merged := *c
+		mergeFields(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(source))
+		merged.Sources = nil
+		configs = append(configs, &merged)
+	}
+	return configs
+}
+
+// mergeFields 将 src 中的非零字段写入 dst，嵌套的结构体逐字段合并
+func mergeFields(dst, src reflect.Value) {
+	for i := 0; i < src.NumField(); i++ {
+		field := src.Field(i)
+		if field.Kind() == reflect.Struct {
+			mergeFields(dst.Field(i), field)
+			continue
+		}
+		if !field.IsZero() {
+			dst.Field(i).Set(field)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSourceConfigs(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{
+		"databaseID": "top",
+		"content": {"folder": "content/posts", "archetype": "archetypes/post.md"},
+		"storage": {"type": "local", "local": {"path": "static/images"}},
+		"notion": {"categoryMap": {"技术": "tech", "生活": "life"}},
+		"sources": [
+			{"name": "blog"},
+			{
+				"name": "notes",
+				"databaseID": "notes-db",
+				"content": {"folder": "content/notes"},
+				"storage": {"type": "s3", "s3": {"bucket": "notes"}},
+				"notion": {"categoryMap": {"笔记": "notes"}}
+			}
+		]
+	}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := config.SourceConfigs()
+	if len(sources) != 2 {
+		t.Fatalf("应有 2 个来源，得到 %d", len(sources))
+	}
+
+	blog, notes := sources[0], sources[1]
+	if blog.DatabaseID != "top" || blog.Content.Folder != "content/posts" || blog.Storage.Type != "local" {
+		t.Errorf("blog 应继承顶层配置: %+v", blog)
+	}
+	if notes.DatabaseID != "notes-db" || notes.Content.Folder != "content/notes" {
+		t.Errorf("notes 应覆盖数据库和目录: %+v", notes)
+	}
+	if notes.Content.Archetype != "archetypes/post.md" {
+		t.Errorf("notes 未设置的字段应使用顶层配置，得到 %q", notes.Content.Archetype)
+	}
+	if notes.Storage.Type != "s3" || notes.Storage.S3.Bucket != "notes" {
+		t.Errorf("notes 应使用自己的存储: %+v", notes.Storage)
+	}
+	if len(notes.Notion.CategoryMap) != 1 || notes.Notion.CategoryMap["笔记"] != "notes" {
+		t.Errorf("分类映射应整体替换，得到 %v", notes.Notion.CategoryMap)
+	}
+	if notes.Sources != nil {
+		t.Error("合并后的来源不应再包含来源")
+	}
+
+	// 没有来源时返回顶层配置
+	config.Sources = nil
+	if sources := config.SourceConfigs(); len(sources) != 1 || sources[0] != &config {
+		t.Error("没有来源时应返回顶层配置")
+	}
+}
+
+func TestImageLegacyKeys(t *testing.T) {
+	var config Config
+	if err := json.Unmarshal([]byte(`{"image": {"max_width": 1920, "quality": 85}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.Image.MaxWidth != 1920 || config.Image.Quality != 85 {
+		t.Errorf("应兼容旧的 max_width: %+v", config.Image)
+	}
+}
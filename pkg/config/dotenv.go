@@ -0,0 +1,156 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EnvVar 是 .env 文件中的一个变量
+type EnvVar struct {
+	Key   string
+	Value string
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadDotenv 读取 .env 文件并设置其中的环境变量，已经存在的环境变量不会被覆盖
+//
+// 文件不存在时返回的错误满足 errors.Is(err, os.ErrNotExist)。
+func LoadDotenv(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	vars, err := ParseDotenv(f, os.LookupEnv)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, v := range vars {
+		if _, ok := os.LookupEnv(v.Key); ok {
+			continue
+		}
+		if err := os.Setenv(v.Key, v.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseDotenv 解析 .env 格式的内容
+//
+// 支持 # 注释、export 前缀、单引号（原样保留）、双引号（支持 \n 等转义和多行）
+// 以及 $VAR 和 ${VAR} 展开。展开时 lookup 中的真实环境变量优先于文件中前面定义的值。
+// 错误只包含行号，不包含变量的值，以免泄露密钥。
+func ParseDotenv(r io.Reader, lookup func(string) (string, bool)) ([]EnvVar, error) {
+	defined := make(map[string]string)
+	expand := func(name string) string {
+		// os.Expand 把 $$ 当作名为 $ 的变量，用于输出转义的 \$
+		if name == "$" {
+			return "$"
+		}
+		if value, ok := lookup(name); ok {
+			return value
+		}
+		return defined[name]
+	}
+
+	var vars []EnvVar
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rest, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("第 %d 行格式错误，应为 KEY=value", lineNumber)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		var value string
+		switch {
+		case strings.HasPrefix(rest, "'"):
+			end := strings.Index(rest[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("第 %d 行 %s 的单引号没有闭合", lineNumber, key)
+			}
+			value = rest[1 : end+1]
+		case strings.HasPrefix(rest, `"`):
+			// 双引号的值可以跨行，直到遇到没有转义的双引号
+			start := lineNumber
+			quoted := rest[1:]
+			for {
+				if end := closingQuote(quoted); end >= 0 {
+					quoted = quoted[:end]
+					break
+				}
+				if !scanner.Scan() {
+					return nil, fmt.Errorf("第 %d 行 %s 的双引号没有闭合", start, key)
+				}
+				lineNumber++
+				quoted += "\n" + scanner.Text()
+			}
+			value = os.Expand(unescape(quoted), expand)
+		default:
+			// 没有引号时，空白之后的 # 开始注释
+			if i := strings.Index(rest, " #"); i >= 0 {
+				rest = rest[:i]
+			}
+			value = os.Expand(strings.TrimSpace(rest), expand)
+		}
+
+		defined[key] = value
+		vars = append(vars, EnvVar{Key: key, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// closingQuote 返回第一个没有被反斜杠转义的双引号的位置，没有时返回 -1
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescape 处理双引号中的转义，\$ 保留为 $$，展开时输出为 $
+func unescape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '$':
+			b.WriteString("$$")
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
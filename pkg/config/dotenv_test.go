@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	input := `# Notion 配置
+NOTION_SECRET=secret_abc  # 行尾注释
+export DATABASE_ID = "db-id"
+EMPTY=
+SINGLE='literal $HOME # not a comment'
+DOUBLE="line one\nline two \$5"
+HOME_DIR=${HOME}/notion
+PREFIX=$DATABASE_ID-posts
+KEY="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+`
+	env := map[string]string{"HOME": "/home/me", "DATABASE_ID": "real-db"}
+	vars, err := ParseDotenv(strings.NewReader(input), func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []EnvVar{
+		{"NOTION_SECRET", "secret_abc"},
+		{"DATABASE_ID", "db-id"},
+		{"EMPTY", ""},
+		{"SINGLE", "literal $HOME # not a comment"},
+		{"DOUBLE", "line one\nline two $5"},
+		{"HOME_DIR", "/home/me/notion"},
+		// 展开时真实环境变量优先于文件中的值
+		{"PREFIX", "real-db-posts"},
+		{"KEY", "-----BEGIN KEY-----\nabc\n-----END KEY-----"},
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("得到 %d 个变量，期望 %d: %+v", len(vars), len(want), vars)
+	}
+	for i := range want {
+		if vars[i] != want[i] {
+			t.Errorf("第 %d 个变量为 %+v，期望 %+v", i, vars[i], want[i])
+		}
+	}
+}
+
+func TestParseDotenvErrors(t *testing.T) {
+	for _, input := range []string{
+		"NOT A VARIABLE",
+		"TOKEN='secret_value",
+		"TOKEN=\"secret_value",
+	} {
+		_, err := ParseDotenv(strings.NewReader(input), func(string) (string, bool) { return "", false })
+		if err == nil {
+			t.Errorf("%q 应报错", input)
+			continue
+		}
+		// 错误信息不能包含值
+		if strings.Contains(err.Error(), "secret_value") {
+			t.Errorf("错误信息泄露了值: %v", err)
+		}
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	err := os.WriteFile(path, []byte("NOTION2MD_TEST_NEW=file\nNOTION2MD_TEST_EXISTING=file\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NOTION2MD_TEST_EXISTING", "real")
+	t.Cleanup(func() { os.Unsetenv("NOTION2MD_TEST_NEW") })
+
+	if err := LoadDotenv(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("NOTION2MD_TEST_NEW"); got != "file" {
+		t.Errorf("应设置文件中的变量，得到 %q", got)
+	}
+	if got := os.Getenv("NOTION2MD_TEST_EXISTING"); got != "real" {
+		t.Errorf("真实环境变量应优先，得到 %q", got)
+	}
+
+	if err := LoadDotenv(filepath.Join(t.TempDir(), "missing.env")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("文件不存在时应返回 os.ErrNotExist，得到 %v", err)
+	}
+}
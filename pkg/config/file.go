@@ -0,0 +1,284 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseError 是配置文件中某个位置的错误，Line 和 Column 从 1 开始，未知时为 0
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// position 是 YAML 文件中一个键的位置
+type position struct {
+	line, column int
+}
+
+// decodeFile 按扩展名解析 JSON、YAML 或 TOML 配置文件并写入 c
+//
+// 文件中有 params 时视为 Hugo 站点配置（如 hugo.toml），只读取其中的 params.notion2md 部分。
+func decodeFile(path string, c *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return decodeJSON(path, data, c)
+	case ".yaml", ".yml":
+		return decodeYAML(path, data, c)
+	case ".toml":
+		return decodeTOML(path, data, c)
+	default:
+		return fmt.Errorf("不支持的配置文件格式 %q，可选 .json、.yaml、.yml 或 .toml", ext)
+	}
+}
+
+func decodeJSON(path string, data []byte, c *Config) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return jsonError(path, data, err)
+	}
+	params, ok := lookupKey(doc, "params")
+	if !ok {
+		if err := json.Unmarshal(data, c); err != nil {
+			return jsonError(path, data, err)
+		}
+		return nil
+	}
+
+	var section map[string]json.RawMessage
+	if err := json.Unmarshal(params, &section); err != nil {
+		return &ParseError{Path: path, Err: errors.New("params 应为对象")}
+	}
+	raw, ok := lookupKey(section, "notion2md")
+	if !ok {
+		return &ParseError{Path: path, Err: errors.New("Hugo 站点配置中没有 params.notion2md 部分")}
+	}
+	// 偏移量相对于 params.notion2md 部分，无法换算为文件中的行列，只报告字段路径
+	if err := json.Unmarshal(raw, c); err != nil {
+		return &ParseError{Path: path, Err: fieldError(err, "params.notion2md.")}
+	}
+	return nil
+}
+
+// jsonError 把 encoding/json 错误中的字节偏移量换算为行列
+func jsonError(path string, data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+		err = fieldError(err, "")
+	default:
+		return &ParseError{Path: path, Err: err}
+	}
+
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	// Offset 为出错时已读取的字节数，出错的字符是最后读取的那个
+	before := data[:max(offset-1, 0)]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return &ParseError{Path: path, Line: line, Column: column, Err: err}
+}
+
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func decodeYAML(path string, data []byte, c *Config) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		// yaml.v3 的语法错误只提供行号
+		if m := yamlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return &ParseError{Path: path, Line: line, Err: errors.New(m[2])}
+		}
+		return &ParseError{Path: path, Err: err}
+	}
+	if len(root.Content) == 0 {
+		return nil
+	}
+
+	node, prefix := root.Content[0], ""
+	if key, params := yamlChild(node, "params"); params != nil {
+		if _, node = yamlChild(params, "notion2md"); node == nil {
+			return &ParseError{Path: path, Line: key.Line, Column: key.Column, Err: errors.New("Hugo 站点配置中没有 params.notion2md 部分")}
+		}
+		prefix = "params.notion2md."
+	}
+
+	positions := make(map[string]position)
+	value, err := yamlValue(node, "", positions)
+	if err != nil {
+		return &ParseError{Path: path, Err: err}
+	}
+	if err := decodeValue(value, c); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			pos := positions[strings.ToLower(typeErr.Field)]
+			return &ParseError{Path: path, Line: pos.line, Column: pos.column, Err: fieldError(err, prefix)}
+		}
+		return &ParseError{Path: path, Err: err}
+	}
+	return nil
+}
+
+// yamlChild 返回映射节点中键为 key 的键节点和值节点，键不区分大小写，与 Hugo 相同
+func yamlChild(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if strings.EqualFold(n.Content[i].Value, key) {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// yamlValue 把 YAML 节点转换为可以编码为 JSON 的值，并记录每个键的位置
+//
+// 列表元素与列表共用路径，与 encoding/json 错误中的字段路径一致。
+func yamlValue(n *yaml.Node, path string, positions map[string]position) (interface{}, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias, path, positions)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			child := key.Value
+			if path != "" {
+				child = path + "." + key.Value
+			}
+			if _, ok := positions[strings.ToLower(child)]; !ok {
+				positions[strings.ToLower(child)] = position{key.Line, key.Column}
+			}
+			value, err := yamlValue(n.Content[i+1], child, positions)
+			if err != nil {
+				return nil, err
+			}
+			m[key.Value] = value
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		items := make([]interface{}, 0, len(n.Content))
+		for _, item := range n.Content {
+			value, err := yamlValue(item, path, positions)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	default:
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	}
+}
+
+func decodeTOML(path string, data []byte, c *Config) error {
+	var doc map[string]interface{}
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return &ParseError{Path: path, Line: parseErr.Position.Line, Column: parseErr.Position.Col, Err: errors.New(parseErr.Message)}
+		}
+		return &ParseError{Path: path, Err: err}
+	}
+
+	var section interface{} = doc
+	prefix := ""
+	if params, ok := lookupKey(doc, "params"); ok {
+		table, _ := params.(map[string]interface{})
+		if section, ok = lookupKey(table, "notion2md"); !ok {
+			return &ParseError{Path: path, Err: errors.New("Hugo 站点配置中没有 [params.notion2md] 部分")}
+		}
+		prefix = "params.notion2md."
+	}
+	// BurntSushi/toml 不提供键的位置，类型错误只报告字段路径
+	if err := decodeValue(section, c); err != nil {
+		return &ParseError{Path: path, Err: fieldError(err, prefix)}
+	}
+	return nil
+}
+
+// decodeValue 经由 JSON 把解析出的值写入配置，复用 JSON 标签和自定义的 UnmarshalJSON
+func decodeValue(value interface{}, c *Config) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, c)
+}
+
+// lookupKey 不区分大小写地查找键，与 Hugo 读取站点配置的方式相同
+func lookupKey[V any](m map[string]V, key string) (V, bool) {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// fieldError 把类型错误改写为配置字段路径和期望的值类型，其他错误原样返回
+func fieldError(err error, prefix string) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field == "" {
+		return err
+	}
+	return fmt.Errorf("%s%s 应为%s，实际为 %s", prefix, typeErr.Field, typeName(typeErr.Type), typeErr.Value)
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "字符串"
+	case reflect.Bool:
+		return "布尔值"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "数字"
+	case reflect.Slice, reflect.Array:
+		return "列表"
+	}
+	return "对象"
+}
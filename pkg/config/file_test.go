@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFormats(t *testing.T) {
+	files := map[string]string{
+		"notion2md.json": `{
+			"databaseID": "db",
+			"rateLimit": 2,
+			"content": {"folder": "content/blog"},
+			"notion": {"categoryMap": {"技术": "tech"}, "query": {"filter": {"property": "Status", "status": {"equals": "Ready"}}}},
+			"image": {"formats": ["webp"]}
+		}`,
+		"notion2md.yaml": `
+# 注释
+databaseID: db
+rateLimit: 2
+content:
+  folder: content/blog
+notion:
+  categoryMap:
+    技术: tech
+  query:
+    filter:
+      property: Status
+      status:
+        equals: Ready
+image:
+  formats: [webp]
+`,
+		"notion2md.toml": `
+# 注释
+databaseID = "db"
+rateLimit = 2
+
+[content]
+folder = "content/blog"
+
+[notion.categoryMap]
+"技术" = "tech"
+
+[notion.query.filter]
+property = "Status"
+status = { equals = "Ready" }
+
+[image]
+formats = ["webp"]
+`,
+		"hugo.toml": `
+baseURL = "https://example.com/"
+title = "博客"
+
+[params]
+author = "someone"
+
+[params.notion2md]
+databaseID = "db"
+rateLimit = 2
+content = { folder = "content/blog" }
+image = { formats = ["webp"] }
+
+[params.notion2md.notion.categoryMap]
+"技术" = "tech"
+
+[params.notion2md.notion.query.filter]
+property = "Status"
+status = { equals = "Ready" }
+`,
+		"hugo.yaml": `
+baseURL: https://example.com/
+params:
+  notion2md:
+    databaseID: db
+    rateLimit: 2
+    content: {folder: content/blog}
+    notion:
+      categoryMap: {技术: tech}
+      query:
+        filter: {property: Status, status: {equals: Ready}}
+    image: {formats: [webp]}
+`,
+		"hugo.json": `{
+			"baseURL": "https://example.com/",
+			"Params": {"Notion2md": {
+				"databaseID": "db",
+				"rateLimit": 2,
+				"content": {"folder": "content/blog"},
+				"notion": {"categoryMap": {"技术": "tech"}, "query": {"filter": {"property": "Status", "status": {"equals": "Ready"}}}},
+				"image": {"formats": ["webp"]}
+			}}
+		}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			c, err := Load(writeConfig(t, name, content), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.DatabaseID != "db" || c.RateLimit != 2 || c.Content.Folder != "content/blog" {
+				t.Errorf("顶层字段 = %q %v %q", c.DatabaseID, c.RateLimit, c.Content.Folder)
+			}
+			if c.Notion.CategoryMap["技术"] != "tech" {
+				t.Errorf("categoryMap = %v", c.Notion.CategoryMap)
+			}
+			if filter := strings.ReplaceAll(string(c.Notion.Query.Filter), " ", ""); !strings.Contains(filter, `"equals":"Ready"`) {
+				t.Errorf("filter = %s", filter)
+			}
+			if len(c.Image.Formats) != 1 || c.Image.Formats[0] != "webp" {
+				t.Errorf("image.formats = %v", c.Image.Formats)
+			}
+			// 未设置的字段仍然使用默认值
+			if c.Storage.Type != "local" {
+				t.Errorf("storage.type = %q", c.Storage.Type)
+			}
+		})
+	}
+}
+
+func TestLoadParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, content string
+		want          string
+		line, column  int
+	}{
+		{"bad.json", "{\n  \"databaseID\": \"db\",\n  \"target\" \"hugo\"\n}", "", 3, 12},
+		{"type.json", "{\n  \"databaseID\": \"db\",\n  \"rateLimit\": \"fast\"\n}", "rateLimit 应为数字", 3, 21},
+		{"bad.yaml", "databaseID: db\ncontent:\n\tfolder: a\n", "", 3, 0},
+		{"type.yaml", "databaseID: db\ncontent:\n  folder: [a, b]\n", "content.folder 应为字符串", 3, 3},
+		{"bad.toml", "databaseID = \"db\"\ntarget = hugo\n", "", 2, 10},
+		{"type.toml", "databaseID = \"db\"\n[blocks]\nstrict = \"yes\"\n", "blocks.strict 应为布尔值", 0, 0},
+		{"hugo.toml", "title = \"博客\"\n[params.notion2md]\nrateLimit = \"fast\"\n", "params.notion2md.rateLimit 应为数字", 0, 0},
+		{"hugo.yaml", "title: 博客\nparams:\n  author: someone\n", "没有 params.notion2md", 2, 1},
+		{"notion2md.ini", "databaseID = db\n", "不支持的配置文件格式", 0, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.name, tt.content), nil, nil)
+			if err == nil {
+				t.Fatal("期望返回错误")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("错误 %q 中没有 %q", err, tt.want)
+			}
+			var parseErr *ParseError
+			if tt.line == 0 {
+				return
+			}
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("错误 %v 不是 *ParseError", err)
+			}
+			if parseErr.Line != tt.line || parseErr.Column != tt.column {
+				t.Errorf("位置 = %d:%d，期望 %d:%d（%v）", parseErr.Line, parseErr.Column, tt.line, tt.column, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EnvPrefix 是由配置结构生成的环境变量名的前缀
+const EnvPrefix = "NOTION2MD_"
+
+// Field 是配置中可以通过环境变量和命令行参数设置的字段
+type Field struct {
+	// Path 为字段的 JSON 路径，例如 storage.s3.bucket，也是命令行参数名
+	Path string
+	// Env 为环境变量名，例如 NOTION2MD_STORAGE_S3_BUCKET
+	Env string
+	// Alias 为可选的简短环境变量名，例如 S3_BUCKET，优先级低于 Env
+	Alias string
+
+	index []int
+	typ   reflect.Type
+}
+
+// Fields 返回配置结构中所有可设置的字段，顺序与结构定义相同
+//
+// 字段由 JSON 标签生成，sources 不能通过环境变量和命令行参数设置。
+func Fields() []Field {
+	return collectFields(reflect.TypeOf(Config{}), "", nil)
+}
+
+func collectFields(t reflect.Type, prefix string, index []int) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "sources" {
+			continue
+		}
+		path := prefix + name
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectFields(f.Type, path+".", fieldIndex)...)
+			continue
+		}
+		fields = append(fields, Field{
+			Path:  path,
+			Env:   EnvPrefix + envName(path),
+			Alias: f.Tag.Get("env"),
+			index: fieldIndex,
+			typ:   f.Type,
+		})
+	}
+	return fields
+}
+
+// envName 将 JSON 路径转换为环境变量名，例如 storage.s3.urlPrefix 转换为 STORAGE_S3_URL_PREFIX
+func envName(path string) string {
+	var b strings.Builder
+	runes := []rune(path)
+	for i, r := range runes {
+		switch {
+		case r == '.':
+			b.WriteRune('_')
+		case unicode.IsUpper(r) && i > 0 && runes[i-1] != '.' && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			// 驼峰的每个单词之间加下划线，连续的大写字母（如 ID、URL）视为一个单词
+			b.WriteRune('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// Type 返回字段值的写法，用于帮助信息
+func (f Field) Type() string {
+	switch f.typ.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.String {
+			return "list"
+		}
+	}
+	return "json"
+}
+
+// Set 按字段类型解析 value 并写入配置
+//
+// 字符串原样写入，布尔值和数字按 Go 的写法解析，字符串列表以逗号分隔，
+// 映射、过滤条件等其他类型使用 JSON。
+func (f Field) Set(c *Config, value string) error {
+	v := reflect.ValueOf(c).Elem().FieldByIndex(f.index)
+	switch f.typ.Kind() {
+	case reflect.String:
+		v.SetString(value)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s 应为 true 或 false: %q", f.Path, value)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s 应为整数: %q", f.Path, value)
+		}
+		v.SetInt(int64(n))
+		return nil
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s 应为数字: %q", f.Path, value)
+		}
+		v.SetFloat(n)
+		return nil
+	case reflect.Slice:
+		if f.typ.Elem().Kind() == reflect.String {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v.Set(reflect.ValueOf(items))
+			return nil
+		}
+	}
+
+	target := reflect.New(f.typ)
+	if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+		return fmt.Errorf("%s 应为 JSON: %w", f.Path, err)
+	}
+	v.Set(target.Elem())
+	return nil
+}
+
+// ApplyEnv 用环境变量覆盖配置，lookup 通常为 os.LookupEnv
+//
+// 值为空的环境变量视为未设置，GitHub Action 会把未填写的输入设为空字符串。
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, field := range Fields() {
+		value, _ := lookup(field.Env)
+		if value == "" && field.Alias != "" {
+			value, _ = lookup(field.Alias)
+		}
+		if value == "" {
+			continue
+		}
+		if err := field.Set(c, value); err != nil {
+			return fmt.Errorf("环境变量 %s: %w", field.Env, err)
+		}
+	}
+	return nil
+}
+
+// Flags 记录命令行中设置的配置字段，按出现的顺序应用
+type Flags struct {
+	values []flagValue
+}
+
+type flagValue struct {
+	field Field
+	value string
+}
+
+// RegisterFlags 为每个配置字段在 fs 中注册一个同名的命令行参数，例如 -storage.s3.bucket
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{}
+	flags.Register(fs)
+	return flags
+}
+
+// Register 在 fs 中注册每个配置字段的参数
+//
+// 同一个 Flags 可以注册到多个 FlagSet，例如全局参数和子命令的参数，设置的值按解析顺序应用。
+func (f *Flags) Register(fs *flag.FlagSet) {
+	for _, field := range Fields() {
+		usage := fmt.Sprintf("覆盖配置 %s（环境变量 %s）", field.Path, field.Env)
+		if field.typ.Kind() != reflect.Bool {
+			// 帮助信息中用反引号中的词作为参数值的名称
+			usage = fmt.Sprintf("覆盖配置 %s，值为 `%s`（环境变量 %s）", field.Path, field.Type(), field.Env)
+		}
+		fs.Var(&fieldFlag{field: field, flags: f}, field.Path, usage)
+	}
+}
+
+// Apply 把命令行中设置的字段写入配置
+func (f *Flags) Apply(c *Config) error {
+	if f == nil {
+		return nil
+	}
+	for _, v := range f.values {
+		if err := v.field.Set(c, v.value); err != nil {
+			return fmt.Errorf("参数 -%s: %w", v.field.Path, err)
+		}
+	}
+	return nil
+}
+
+// fieldFlag 实现 flag.Value，解析时先在空配置上检查取值
+type fieldFlag struct {
+	field Field
+	flags *Flags
+	value string
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+func (f *fieldFlag) Set(value string) error {
+	if err := f.field.Set(&Config{}, value); err != nil {
+		return err
+	}
+	f.value = value
+	f.flags.values = append(f.flags.values, flagValue{field: f.field, value: value})
+	return nil
+}
+
+// IsBoolFlag 让布尔字段可以写成 -blocks.strict 而不需要 =true
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.field.typ.Kind() == reflect.Bool
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	for path, want := range map[string]string{
+		"databaseID":              "DATABASE_ID",
+		"rateLimit":               "RATE_LIMIT",
+		"storage.s3.urlPrefix":    "STORAGE_S3_URL_PREFIX",
+		"blocks.tableOfContents":  "BLOCKS_TABLE_OF_CONTENTS",
+		"notion.categoryMap":      "NOTION_CATEGORY_MAP",
+		"html.fragment":           "HTML_FRAGMENT",
+		"notion.status.toDelete":  "NOTION_STATUS_TO_DELETE",
+		"code.mermaidShortcode":   "CODE_MERMAID_SHORTCODE",
+		"notion.properties.title": "NOTION_PROPERTIES_TITLE",
+	} {
+		if got := envName(path); got != want {
+			t.Errorf("envName(%q) = %q，期望 %q", path, got, want)
+		}
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{
+		"databaseID": "from-file",
+		"content": {"folder": "content/file"},
+		"storage": {"type": "s3", "s3": {"bucket": "file-bucket"}}
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"NOTION2MD_CONTENT_FOLDER":        "content/env",
+		"S3_BUCKET":                       "alias-bucket",
+		"S3_ENDPOINT":                     "https://minio.example.com",
+		"NOTION2MD_STORAGE_S3_URL_PREFIX": "https://cdn.example.com",
+		"NOTION2MD_NOTION_CATEGORY_MAP":   `{"技术": "tech"}`,
+		"NOTION2MD_BLOCKS_STRICT":         "true",
+		"NOTION2MD_IMAGE_FORMATS":         "jpg, webp",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse([]string{"-content.folder", "content/flag", "-rateLimit=10", "-html.fragment"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(path, lookup, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DatabaseID != "from-file" {
+		t.Errorf("未覆盖的字段应来自配置文件，得到 %q", c.DatabaseID)
+	}
+	if c.Content.Folder != "content/flag" {
+		t.Errorf("参数应覆盖环境变量和配置文件，得到 %q", c.Content.Folder)
+	}
+	if c.Storage.S3.Bucket != "alias-bucket" || c.Storage.S3.URLPrefix != "https://cdn.example.com" ||
+		c.Storage.S3.Endpoint != "https://minio.example.com" {
+		t.Errorf("环境变量应覆盖配置文件: %+v", c.Storage.S3)
+	}
+	if c.Notion.CategoryMap["技术"] != "tech" || !c.Blocks.Strict || len(c.Image.Formats) != 2 {
+		t.Errorf("映射、布尔值和列表应能从环境变量设置: %+v %+v %+v", c.Notion.CategoryMap, c.Blocks, c.Image)
+	}
+	if c.RateLimit != 10 || !c.HTML.Fragment {
+		t.Errorf("数字和布尔参数未生效: %v %v", c.RateLimit, c.HTML.Fragment)
+	}
+	if c.Target != "hugo" || c.Storage.S3.PathPrefix != "images" {
+		t.Errorf("未设置的字段应使用默认值: %q %q", c.Target, c.Storage.S3.PathPrefix)
+	}
+
+	// 带前缀的环境变量优先于简短的别名
+	env["NOTION2MD_STORAGE_S3_BUCKET"] = "prefixed-bucket"
+	if c, err = Load(path, lookup, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.Storage.S3.Bucket != "prefixed-bucket" {
+		t.Errorf("应使用带前缀的环境变量，得到 %q", c.Storage.S3.Bucket)
+	}
+}
+
+func TestLoadWithoutFile(t *testing.T) {
+	c, err := Load("", func(key string) (string, bool) {
+		if key == "DATABASE_ID" {
+			return "db", true
+		}
+		return "", false
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DatabaseID != "db" || c.Content.Folder != "content/posts" {
+		t.Errorf("没有配置文件时应使用环境变量和默认值: %+v", c)
+	}
+}
+
+func TestInvalidOverrides(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(new(strings.Builder))
+	RegisterFlags(fs)
+	if err := fs.Parse([]string{"-rateLimit", "fast"}); err == nil {
+		t.Error("非数字的 rateLimit 应报错")
+	}
+
+	_, err := Load("", func(key string) (string, bool) {
+		if key == "NOTION2MD_NOTION_CATEGORY_MAP" {
+			return "{not json", true
+		}
+		return "", false
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "NOTION2MD_NOTION_CATEGORY_MAP") {
+		t.Errorf("错误应包含环境变量名: %v", err)
+	}
+}
+
+// TestREADMEListsEveryField 确保 README 的环境变量表包含所有字段
+func TestREADMEListsEveryField(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range Fields() {
+		if !strings.Contains(string(data), "`"+field.Env+"`") {
+			t.Errorf("README 缺少 %s", field.Env)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Validate 检查配置，一次返回所有问题
+//
+// 配置了来源时逐个检查合并后的来源，问题前加上来源名称。返回的错误可以用 errors.As 取得 *ValidationError。
+func (c *Config) Validate() error {
+	var problems []string
+	if c.RateLimit < 0 {
+		problems = append(problems, "rateLimit 不能为负数")
+	}
+
+	for _, source := range c.SourceConfigs() {
+		prefix := ""
+		if len(c.Sources) > 0 {
+			name := source.Name
+			if name == "" {
+				name = source.DatabaseID
+			}
+			prefix = fmt.Sprintf("来源 %s: ", name)
+		}
+		for _, problem := range source.validateSource() {
+			problems = append(problems, prefix+problem)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validateSource 检查单个来源的配置
+func (c *Config) validateSource() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.DatabaseID == "" {
+		add("未设置 databaseID")
+	}
+
+	switch c.Target {
+	case "", "hugo":
+		if c.Content.Archetype == "" {
+			add("hugo 目标需要设置 content.archetype")
+		} else if err := checkFile(c.Content.Archetype); err != nil {
+			add("content.archetype %v", err)
+		}
+	case "hexo":
+		// 草稿写入 content.folder 同级的 _drafts
+		if filepath.Base(filepath.Clean(c.Content.Folder)) != "_posts" {
+			add("hexo 目标的 content.folder 应为以 _posts 结尾的目录（例如 source/_posts），当前为 %q", c.Content.Folder)
+		}
+	case "jekyll", "zola", "astro":
+	case "html":
+		if c.HTML.Template != "" {
+			if err := checkFile(c.HTML.Template); err != nil {
+				add("html.template %v", err)
+			}
+		}
+	default:
+		add("不支持的 target: %q，可选 hugo、jekyll、hexo、zola、astro 或 html", c.Target)
+	}
+	if c.Target != "" && c.Target != "hugo" && c.Target != "html" && c.Content.Template != "" {
+		if err := checkFile(c.Content.Template); err != nil {
+			add("content.template %v", err)
+		}
+	}
+
+	switch c.Flavor {
+	case "", FlavorHugo, FlavorGFM, FlavorCommonMark:
+	default:
+		add("不支持的 flavor: %q，可选 hugo、gfm 或 commonmark", c.Flavor)
+	}
+
+	switch c.Storage.Type {
+	case "", "local":
+	case "s3":
+		if c.Storage.S3.Bucket == "" {
+			add("storage.s3.bucket 未设置")
+		}
+		if c.Storage.S3.Region == "" {
+			add("storage.s3.region 未设置")
+		}
+		if c.Storage.S3.URLPrefix == "" {
+			add("storage.s3.urlPrefix 未设置")
+		}
+	default:
+		add("不支持的 storage.type: %q，可选 local 或 s3", c.Storage.Type)
+	}
+
+	if len(c.Notion.CategoryMap) == 0 {
+		add("notion.categoryMap 为空，所有设置了分类的文章都会被跳过")
+	}
+	// 使用默认查询时需要待发布状态，发布后需要已发布状态
+	if len(c.Notion.Query.Filter) == 0 && c.Notion.Status.Ready == "" {
+		add("未设置 notion.query.filter 时需要设置 notion.status.ready")
+	}
+	if c.Notion.Status.Published == "" {
+		add("notion.status.published 未设置")
+	}
+	if c.Notion.Status.ToDelete != "" && c.Notion.Status.Deleted == "" {
+		add("设置了 notion.status.toDelete 时需要设置 notion.status.deleted")
+	}
+
+	switch c.Blocks.Unsupported {
+	case "", "skip", "comment":
+	default:
+		add("不支持的 blocks.unsupported: %q，可选 skip 或 comment", c.Blocks.Unsupported)
+	}
+	for blockType, path := range c.Blocks.Templates {
+		if err := checkFile(path); err != nil {
+			add("blocks.templates.%s %v", blockType, err)
+		}
+	}
+
+	return problems
+}
+
+// checkFile 检查文件是否存在且不是目录
+func checkFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("文件不存在: %s", path)
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("是目录而不是文件: %s", path)
+	}
+	return nil
+}
+
+// ValidationError 包含配置中的所有问题
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	message := fmt.Sprintf("配置有 %d 个问题:", len(e.Problems))
+	for _, problem := range e.Problems {
+		message += "\n  - " + problem
+	}
+	return message
+}
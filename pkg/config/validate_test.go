@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validConfig() *Config {
+	c := &Config{DatabaseID: "db"}
+	c.Content.Archetype = filepath.Join("..", "..", "archetypes", "post.md")
+	c.Notion.CategoryMap = map[string]string{"技术": "tech"}
+	c.Notion.Status.Ready = "Ready"
+	c.Notion.Status.Published = "Published"
+	c.SetDefaults()
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("有效的配置不应报错: %v", err)
+	}
+
+	c := validConfig()
+	c.Content.Archetype = "missing.md"
+	c.Storage.Type = "ftp"
+	c.Notion.CategoryMap = nil
+	c.Flavor = "markdown"
+	c.Blocks.Unsupported = "drop"
+
+	var validationErr *ValidationError
+	if err := c.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("应返回 ValidationError，得到 %v", err)
+	}
+	// 所有问题一次报告
+	for _, want := range []string{"missing.md", "storage.type", "categoryMap", "flavor", "blocks.unsupported"} {
+		found := false
+		for _, problem := range validationErr.Problems {
+			if strings.Contains(problem, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("缺少 %s 的问题: %v", want, validationErr.Problems)
+		}
+	}
+	if len(validationErr.Problems) != 5 {
+		t.Errorf("应有 5 个问题，得到 %d: %v", len(validationErr.Problems), validationErr.Problems)
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	c := validConfig()
+	c.Sources = []Config{
+		{Name: "blog"},
+		{Name: "media", Storage: StorageConfig{Type: "s3"}},
+	}
+
+	var validationErr *ValidationError
+	if err := c.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("应返回 ValidationError，得到 %v", err)
+	}
+	for _, problem := range validationErr.Problems {
+		if !strings.HasPrefix(problem, "来源 media: storage.s3.") {
+			t.Errorf("只有 media 来源缺少 S3 配置，得到: %s", problem)
+		}
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("应报告 bucket、region 和 urlPrefix，得到 %v", validationErr.Problems)
+	}
+}
+
+func TestValidateHexoFolder(t *testing.T) {
+	for _, tt := range []struct {
+		folder string
+		valid  bool
+	}{
+		{"source/_posts", true},
+		{"blog/source/_posts/", true},
+		{"source", false},
+		{"content/posts", false},
+	} {
+		c := validConfig()
+		c.Target = "hexo"
+		c.Content.Folder = tt.folder
+		err := c.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s 不应报错: %v", tt.folder, err)
+		}
+		if !tt.valid && (err == nil || !strings.Contains(err.Error(), "_posts")) {
+			t.Errorf("%s 应报告 _posts，得到 %v", tt.folder, err)
+		}
+	}
+}
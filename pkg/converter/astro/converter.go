@@ -0,0 +1,40 @@
+package astro
+
+import (
+	"time"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+)
+
+// Dialect 实现 Astro 内容集合（src/content/blog）的约定，嵌入内容输出为 HTML
+type Dialect struct{}
+
+func New(blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *site.Converter {
+	return site.New(Dialect{}, blockProcessor, metaProcessor)
+}
+
+func (Dialect) Shortcodes() notion.Shortcodes {
+	return notion.Shortcodes{}
+}
+
+func (Dialect) FrontMatter(a *site.Article) string {
+	var f site.Fields
+	f.Add("title", a.String("title"))
+	f.Add("description", a.String("description"))
+	f.Add("pubDate", a.Date.Format(time.RFC3339))
+	if updated := a.Time("lastmod"); !updated.IsZero() {
+		f.Add("updatedDate", updated.Format(time.RFC3339))
+	}
+	f.Add("heroImage", a.String("cover"))
+	f.Add("author", a.String("author"))
+	f.Add("categories", a.Strings("categories"))
+	f.Add("tags", a.Strings("tags"))
+	f.Add("draft", a.Bool("draft"))
+	return site.YAML(f)
+}
+
+func (Dialect) Path(a *site.Article) string {
+	return a.Slug + ".md"
+}
@@ -0,0 +1,65 @@
+package astro
+
+import (
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDialect(t *testing.T) {
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name        string
+		metadata    map[string]interface{}
+		path        string
+		frontMatter string
+	}{
+		{
+			name: "文章",
+			metadata: map[string]interface{}{
+				"title":       "你好",
+				"description": "第一篇",
+				"lastmod":     "2024-05-02T09:30:00Z",
+				"cover":       "/images/cover.png",
+				"author":      "张三",
+				"categories":  []string{"技术"},
+				"tags":        []string{"Go"},
+			},
+			path: "ni-hao.md",
+			frontMatter: `---
+title: "你好"
+description: "第一篇"
+pubDate: "2024-05-01T08:00:00Z"
+updatedDate: "2024-05-02T09:30:00Z"
+heroImage: "/images/cover.png"
+author: "张三"
+categories: ["技术"]
+tags: ["Go"]
+draft: false
+---
+`,
+		},
+		{
+			name:     "草稿",
+			metadata: map[string]interface{}{"title": "草稿", "draft": true},
+			path:     "cao-gao.md",
+			frontMatter: `---
+title: "草稿"
+pubDate: "2024-05-01T08:00:00Z"
+draft: true
+---
+`,
+		},
+	} {
+		a := &site.Article{Metadata: tt.metadata, Slug: site.Slugify(notionapi.Page{}, tt.metadata), Category: site.Category(tt.metadata), Date: date}
+		if got := (Dialect{}).Path(a); got != tt.path {
+			t.Errorf("%s 的路径为 %q，期望 %q", tt.name, got, tt.path)
+		}
+		if got := (Dialect{}).FrontMatter(a); got != tt.frontMatter {
+			t.Errorf("%s 的 front matter 为:\n%s\n期望:\n%s", tt.name, got, tt.frontMatter)
+		}
+	}
+}
@@ -1,11 +1,18 @@
 package converter
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/jomei/notionapi"
 )
 
+// ErrSkipPage 表示文章因未配置分类映射等原因被跳过
+var ErrSkipPage = errors.New("跳过文章")
+
 // Converter 定义了内容转换器的接口
 type Converter interface {
 	// Convert 将 Notion 页面转换为目标格式
@@ -16,6 +23,36 @@ type Converter interface {
 
 	// SetTemplate 设置模板
 	SetTemplate(template string) error
+
+	// SetWriter 设置转换结果的写入方式，默认写入文件系统
+	SetWriter(w Writer)
+}
+
+// Locator 由能在不读取页面内容时确定输出位置的转换器实现，删除文章时使用
+type Locator interface {
+	// Locate 返回页面的输出文件，以及本地媒体相对保存目录的文章目录（分类/文章）
+	//
+	// 未配置分类映射的页面没有输出，返回 ErrSkipPage。
+	Locate(page notionapi.Page) (file string, mediaDir string, err error)
+}
+
+// Writer 定义了转换结果的写入方式
+type Writer interface {
+	// WriteFile 写入一个输出文件，path 包含输出目录
+	WriteFile(path string, data []byte) error
+}
+
+// DiskWriter 把转换结果写入文件系统，并创建所需的目录
+type DiskWriter struct{}
+
+func (DiskWriter) WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("创建文件失败: %w", err)
+	}
+	return nil
 }
 
 // BlockProcessor 定义了块处理器的接口
@@ -23,10 +60,20 @@ type BlockProcessor interface {
 	// ProcessBlock 处理单个块
 	ProcessBlock(block notionapi.Block, w io.Writer) error
 
+	// ProcessBlocks 处理同级的一组块，列表等块需要相邻块才能正确结束
+	ProcessBlocks(blocks []notionapi.Block, w io.Writer) error
+
 	// SupportedBlocks 返回支持的块类型
 	SupportedBlocks() []string
 }
 
+// SkippedBlock 描述一个因类型不受支持而未渲染的块
+type SkippedBlock struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	URL  string `json:"url"`
+}
+
 // MediaHandler 定义了媒体处理器的接口
 type MediaHandler interface {
 	// SaveMedia 保存媒体文件并返回可访问的 URL
@@ -41,3 +88,9 @@ type MetadataProcessor interface {
 	// ProcessMetadata 处理页面元数据
 	ProcessMetadata(page notionapi.Page) (map[string]interface{}, error)
 }
+
+// LinkResolver 定义了页面链接解析器的接口
+type LinkResolver interface {
+	// ResolvePage 返回被链接页面的标题和站内链接地址
+	ResolvePage(pageID notionapi.PageID) (title string, link string, err error)
+}
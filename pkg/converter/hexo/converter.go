@@ -0,0 +1,57 @@
+package hexo
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+)
+
+// Dialect 实现 Hexo 的约定：文章位于 source/_posts，草稿位于同级的 _drafts
+type Dialect struct{}
+
+func New(blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *site.Converter {
+	return site.New(Dialect{}, blockProcessor, metaProcessor)
+}
+
+func (Dialect) Shortcodes() notion.Shortcodes {
+	// Hexo 内置的标签插件
+	return notion.Shortcodes{
+		YouTube: "{%% youtube %s %%}",
+	}
+}
+
+func (Dialect) FrontMatter(a *site.Article) string {
+	var f site.Fields
+	f.Add("title", a.String("title"))
+	f.Add("date", a.Date)
+	f.Add("updated", a.Time("lastmod"))
+	f.Add("categories", a.Strings("categories"))
+	f.Add("tags", a.Strings("tags"))
+	f.Add("description", a.String("description"))
+	f.Add("author", a.String("author"))
+	f.Add("cover", a.String("cover"))
+	if a.Bool("toc") {
+		f.Add("toc", true)
+	}
+	f.Add("comments", a.Bool("comments"))
+	f.Add("permalink", a.String("slug"))
+	return site.YAML(f)
+}
+
+// CheckOutput 要求输出目录为 _posts，草稿写入同级的 _drafts，不会写到 source 目录之外
+func (Dialect) CheckOutput(outputPath string) error {
+	if filepath.Base(filepath.Clean(outputPath)) != "_posts" {
+		return fmt.Errorf("hexo 的输出目录应为 _posts（例如 source/_posts），草稿写入同级的 _drafts，当前为 %s", outputPath)
+	}
+	return nil
+}
+
+func (Dialect) Path(a *site.Article) string {
+	if a.Bool("draft") {
+		return filepath.Join("..", "_drafts", a.Slug+".md")
+	}
+	return a.Slug + ".md"
+}
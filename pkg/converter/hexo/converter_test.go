@@ -0,0 +1,89 @@
+package hexo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDialect(t *testing.T) {
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name        string
+		metadata    map[string]interface{}
+		path        string
+		frontMatter string
+	}{
+		{
+			name: "文章",
+			metadata: map[string]interface{}{
+				"title":       "你好",
+				"lastmod":     "2024-05-02T09:30:00Z",
+				"categories":  []string{"技术"},
+				"tags":        []string{"Go"},
+				"description": "第一篇",
+				"cover":       "/images/cover.png",
+				"comments":    true,
+				"slug":        "hello",
+			},
+			path: "ni-hao.md",
+			frontMatter: `---
+title: "你好"
+date: 2024-05-01 08:00:00 +0000
+updated: 2024-05-02 09:30:00 +0000
+categories: ["技术"]
+tags: ["Go"]
+description: "第一篇"
+cover: "/images/cover.png"
+comments: true
+permalink: "hello"
+---
+`,
+		},
+		{
+			// 草稿写入 _posts 同级的 _drafts
+			name:     "草稿",
+			metadata: map[string]interface{}{"title": "草稿", "draft": true, "toc": true},
+			path:     filepath.Join("..", "_drafts", "cao-gao.md"),
+			frontMatter: `---
+title: "草稿"
+date: 2024-05-01 08:00:00 +0000
+toc: true
+comments: false
+---
+`,
+		},
+	} {
+		a := &site.Article{Metadata: tt.metadata, Slug: site.Slugify(notionapi.Page{}, tt.metadata), Category: site.Category(tt.metadata), Date: date}
+		if got := (Dialect{}).Path(a); got != tt.path {
+			t.Errorf("%s 的路径为 %q，期望 %q", tt.name, got, tt.path)
+		}
+		if got := (Dialect{}).FrontMatter(a); got != tt.frontMatter {
+			t.Errorf("%s 的 front matter 为:\n%s\n期望:\n%s", tt.name, got, tt.frontMatter)
+		}
+	}
+}
+
+func TestCheckOutput(t *testing.T) {
+	for _, tt := range []struct {
+		path  string
+		valid bool
+	}{
+		{"source/_posts", true},
+		{"/blog/source/_posts/", true},
+		{"source", false},
+		{"content/posts", false},
+	} {
+		err := (Dialect{}).CheckOutput(tt.path)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s 的检查结果为 %v", tt.path, err)
+		}
+	}
+	if err := New(nil, nil).SetOutput("content"); err == nil {
+		t.Error("SetOutput 应检查输出目录")
+	}
+}
@@ -0,0 +1,457 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+// BlockProcessor 将 Notion 块渲染为语义化的 HTML
+//
+// 它复用 Markdown 块处理器的渲染器注册表，只替换内置渲染器，
+// 因此严格模式、未支持块的回退、块模板和代码语言映射与 Markdown 输出一致。
+type BlockProcessor struct {
+	*notion.BlockProcessor
+	headings []heading
+}
+
+type heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// NewBlockProcessor 在块处理器上注册 HTML 渲染器，之后注册的块模板仍会覆盖它们
+func NewBlockProcessor(processor *notion.BlockProcessor) *BlockProcessor {
+	p := &BlockProcessor{BlockProcessor: processor}
+	p.registerBuiltins()
+	return p
+}
+
+// registerBuiltins 用 HTML 渲染器替换内置的 Markdown 渲染器
+func (p *BlockProcessor) registerBuiltins() {
+	p.Register(notionapi.BlockTypeHeading1, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.Heading1Block) error {
+		return p.renderHeading(w, b.Heading1.RichText, 2)
+	}))
+	p.Register(notionapi.BlockTypeHeading2, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.Heading2Block) error {
+		return p.renderHeading(w, b.Heading2.RichText, 3)
+	}))
+	p.Register(notionapi.BlockTypeHeading3, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.Heading3Block) error {
+		return p.renderHeading(w, b.Heading3.RichText, 4)
+	}))
+	p.Register(notionapi.BlockTypeParagraph, notion.RendererFor(p.renderParagraph))
+	p.Register(notionapi.BlockTypeBulletedListItem, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.BulletedListItemBlock) error {
+		return p.renderListItem(ctx, w, "", b.BulletedListItem.RichText, b.BulletedListItem.Children)
+	}))
+	p.Register(notionapi.BlockTypeNumberedListItem, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.NumberedListItemBlock) error {
+		return p.renderListItem(ctx, w, "", b.NumberedListItem.RichText, b.NumberedListItem.Children)
+	}))
+	p.Register(notionapi.BlockTypeToDo, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.ToDoBlock) error {
+		checked := ""
+		if b.ToDo.Checked {
+			checked = " checked"
+		}
+		prefix := fmt.Sprintf("<input type=\"checkbox\" disabled%s> ", checked)
+		return p.renderListItem(ctx, w, prefix, b.ToDo.RichText, b.ToDo.Children)
+	}))
+	p.Register(notionapi.BlockTypeToggle, notion.RendererFor(p.renderToggle))
+	p.Register(notionapi.BlockTypeQuote, notion.RendererFor(p.renderQuote))
+	p.Register(notionapi.BlockTypeCallout, notion.RendererFor(p.renderCallout))
+	p.Register(notionapi.BlockTypeCode, notion.RendererFor(p.renderCode))
+	p.Register(notionapi.BlockTypeImage, notion.RendererFor(p.renderImage))
+	p.Register(notionapi.BlockTypeVideo, notion.RendererFor(p.renderVideo))
+	p.Register(notionapi.BlockTypeFile, notion.RendererFor(p.renderFile))
+	p.Register(notionapi.BlockTypeBookmark, notion.RendererFor(p.renderBookmark))
+	p.Register(notionapi.BlockTypeEquation, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.EquationBlock) error {
+		_, err := fmt.Fprintf(w, "<div class=\"equation\">\\[%s\\]</div>\n", template.HTMLEscapeString(b.Equation.Expression))
+		return err
+	}))
+	p.Register(notionapi.BlockTypeDivider, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.DividerBlock) error {
+		_, err := fmt.Fprintln(w, "<hr>")
+		return err
+	}))
+	p.Register(notionapi.BlockTypeTableBlock, notion.RendererFor(p.renderTable))
+	p.Register(notionapi.BlockTypeColumnList, notion.RendererFor(p.renderColumns))
+	p.Register(notionapi.BlockTypeTableOfContents, notion.RendererFor(func(ctx *notion.RenderContext, w io.Writer, b *notionapi.TableOfContentsBlock) error {
+		// 标题渲染完成后由 Converter 替换为目录
+		_, err := fmt.Fprintln(w, tocPlaceholder)
+		return err
+	}))
+	p.Register(notionapi.BlockTypeLinkToPage, notion.RendererFor(p.renderLinkToPage))
+}
+
+func (p *BlockProcessor) renderHeading(w io.Writer, text []notionapi.RichText, level int) error {
+	plain := plainText(text)
+	id := headingID(plain)
+	p.headings = append(p.headings, heading{Level: level, ID: id, Text: plain})
+	_, err := fmt.Fprintf(w, "<h%d id=\"%s\">%s</h%d>\n", level, attr(id), richText(text), level)
+	return err
+}
+
+func (p *BlockProcessor) renderParagraph(ctx *notion.RenderContext, w io.Writer, block *notionapi.ParagraphBlock) error {
+	if len(block.Paragraph.RichText) > 0 {
+		if _, err := fmt.Fprintf(w, "<p>%s</p>\n", richText(block.Paragraph.RichText)); err != nil {
+			return err
+		}
+	}
+	return ctx.RenderChildren(w, block.Paragraph.Children)
+}
+
+// renderListItem 渲染列表项，同类列表项中的第一项和最后一项负责输出列表标签
+func (p *BlockProcessor) renderListItem(ctx *notion.RenderContext, w io.Writer, prefix string, text []notionapi.RichText, children []notionapi.Block) error {
+	start, end := listTags(ctx.Block)
+
+	var buf bytes.Buffer
+	if !sameType(ctx.Prev, ctx.Block) {
+		buf.WriteString(start + "\n")
+	}
+	fmt.Fprintf(&buf, "<li>%s%s", prefix, richText(text))
+	if len(children) > 0 {
+		buf.WriteString("\n")
+		if err := ctx.RenderChildren(&buf, children); err != nil {
+			return err
+		}
+	}
+	buf.WriteString("</li>\n")
+	if !sameType(ctx.Next, ctx.Block) {
+		buf.WriteString(end + "\n")
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderToggle(ctx *notion.RenderContext, w io.Writer, block *notionapi.ToggleBlock) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<details>\n<summary>%s</summary>\n", richText(block.Toggle.RichText))
+	if err := ctx.RenderChildren(&buf, block.Toggle.Children); err != nil {
+		return err
+	}
+	buf.WriteString("</details>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderQuote(ctx *notion.RenderContext, w io.Writer, block *notionapi.QuoteBlock) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<blockquote>\n<p>%s</p>\n", richText(block.Quote.RichText))
+	if err := ctx.RenderChildren(&buf, block.Quote.Children); err != nil {
+		return err
+	}
+	buf.WriteString("</blockquote>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderCallout(ctx *notion.RenderContext, w io.Writer, block *notionapi.CalloutBlock) error {
+	icon := "💡" // 默认图标
+	if block.Callout.Icon != nil && block.Callout.Icon.Emoji != nil {
+		icon = string(*block.Callout.Icon.Emoji)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<aside class=\"callout\">\n<span class=\"callout-icon\">%s</span>\n<p>%s</p>\n", template.HTMLEscapeString(icon), richText(block.Callout.RichText))
+	if err := ctx.RenderChildren(&buf, block.Callout.Children); err != nil {
+		return err
+	}
+	buf.WriteString("</aside>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderCode(ctx *notion.RenderContext, w io.Writer, block *notionapi.CodeBlock) error {
+	class := ""
+	if language := p.CodeLanguage(block.Code.Language); language != "" {
+		class = fmt.Sprintf(" class=\"language-%s\"", attr(language))
+	}
+	code := fmt.Sprintf("<pre><code%s>%s</code></pre>", class, template.HTMLEscapeString(plainText(block.Code.RichText)))
+
+	if len(block.Code.Caption) == 0 {
+		_, err := fmt.Fprintln(w, code)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "<figure>\n%s\n<figcaption>%s</figcaption>\n</figure>\n", code, richText(block.Code.Caption))
+	return err
+}
+
+func (p *BlockProcessor) renderImage(ctx *notion.RenderContext, w io.Writer, block *notionapi.ImageBlock) error {
+	url := block.Image.GetURL()
+
+	// 如果配置了媒体处理器，使用它处理图片
+	if ctx.MediaHandler != nil && url != "" {
+		newURL, err := ctx.MediaHandler.SaveMedia(url)
+		if err != nil {
+			return fmt.Errorf("处理图片失败: %w", err)
+		}
+		url = newURL
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<figure>\n<img src=\"%s\" alt=\"%s\" loading=\"lazy\">\n", attr(url), attr(plainText(block.Image.Caption)))
+	if len(block.Image.Caption) > 0 {
+		fmt.Fprintf(&buf, "<figcaption>%s</figcaption>\n", richText(block.Image.Caption))
+	}
+	buf.WriteString("</figure>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderVideo(ctx *notion.RenderContext, w io.Writer, block *notionapi.VideoBlock) error {
+	var url string
+	switch {
+	case block.Video.External != nil:
+		url = block.Video.External.URL
+	case block.Video.File != nil:
+		url = block.Video.File.URL
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<figure>\n")
+	if id := youTubeID(url); id != "" {
+		fmt.Fprintf(&buf, "<iframe src=\"https://www.youtube.com/embed/%s\" allowfullscreen></iframe>\n", attr(id))
+	} else {
+		fmt.Fprintf(&buf, "<video controls src=\"%s\"></video>\n", attr(url))
+	}
+	if len(block.Video.Caption) > 0 {
+		fmt.Fprintf(&buf, "<figcaption>%s</figcaption>\n", richText(block.Video.Caption))
+	}
+	buf.WriteString("</figure>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderFile(ctx *notion.RenderContext, w io.Writer, block *notionapi.FileBlock) error {
+	var url string
+	switch {
+	case block.File.External != nil:
+		url = block.File.External.URL
+	case block.File.File != nil:
+		url = block.File.File.URL
+	}
+	if url == "" {
+		return nil
+	}
+
+	filename := filepath.Base(strings.Split(url, "?")[0])
+	if strings.HasSuffix(strings.ToLower(filename), ".pdf") {
+		_, err := fmt.Fprintf(w, "<embed src=\"%s\" type=\"application/pdf\" width=\"100%%\" height=\"600px\">\n", attr(url))
+		return err
+	}
+	_, err := fmt.Fprintf(w, "<p><a href=\"%s\" download>%s</a></p>\n", attr(url), template.HTMLEscapeString(filename))
+	return err
+}
+
+func (p *BlockProcessor) renderBookmark(ctx *notion.RenderContext, w io.Writer, block *notionapi.BookmarkBlock) error {
+	title := template.HTML(template.HTMLEscapeString(block.Bookmark.URL))
+	if len(block.Bookmark.Caption) > 0 {
+		title = richText(block.Bookmark.Caption)
+	}
+	_, err := fmt.Fprintf(w, "<p><a href=\"%s\">%s</a></p>\n", attr(block.Bookmark.URL), title)
+	return err
+}
+
+func (p *BlockProcessor) renderTable(ctx *notion.RenderContext, w io.Writer, block *notionapi.TableBlock) error {
+	var rows []*notionapi.TableRowBlock
+	for _, child := range block.Table.Children {
+		if row, ok := child.(*notionapi.TableRowBlock); ok {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<table>\n")
+	if block.Table.HasColumnHeader {
+		buf.WriteString("<thead>\n<tr>")
+		for _, cell := range rows[0].TableRow.Cells {
+			fmt.Fprintf(&buf, "<th scope=\"col\">%s</th>", richText(cell))
+		}
+		buf.WriteString("</tr>\n</thead>\n")
+		rows = rows[1:]
+	}
+	buf.WriteString("<tbody>\n")
+	for _, row := range rows {
+		buf.WriteString("<tr>")
+		for i, cell := range row.TableRow.Cells {
+			if block.Table.HasRowHeader && i == 0 {
+				fmt.Fprintf(&buf, "<th scope=\"row\">%s</th>", richText(cell))
+				continue
+			}
+			fmt.Fprintf(&buf, "<td>%s</td>", richText(cell))
+		}
+		buf.WriteString("</tr>\n")
+	}
+	buf.WriteString("</tbody>\n</table>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderColumns(ctx *notion.RenderContext, w io.Writer, block *notionapi.ColumnListBlock) error {
+	var buf bytes.Buffer
+	buf.WriteString("<div class=\"columns\">\n")
+	for _, column := range block.ColumnList.Children {
+		col, ok := column.(*notionapi.ColumnBlock)
+		if !ok {
+			continue
+		}
+		buf.WriteString("<div class=\"column\">\n")
+		if err := ctx.RenderChildren(&buf, col.Column.Children); err != nil {
+			return err
+		}
+		buf.WriteString("</div>\n")
+	}
+	buf.WriteString("</div>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *BlockProcessor) renderLinkToPage(ctx *notion.RenderContext, w io.Writer, block *notionapi.LinkToPageBlock) error {
+	var title, link string
+	switch block.LinkToPage.Type {
+	case notionapi.BlockType("page_id"):
+		// 无法解析（例如页面未共享给集成）时回退到 Notion 链接
+		if ctx.LinkResolver != nil {
+			if t, l, err := ctx.LinkResolver.ResolvePage(block.LinkToPage.PageID); err == nil {
+				title, link = t, l
+			}
+		}
+		if link == "" {
+			link = notion.NotionURL(string(block.LinkToPage.PageID))
+		}
+	case notionapi.BlockType("database_id"):
+		link = notion.NotionURL(string(block.LinkToPage.DatabaseID))
+	default:
+		return nil
+	}
+
+	if title == "" {
+		title = link
+	}
+	_, err := fmt.Fprintf(w, "<p><a href=\"%s\">%s</a></p>\n", attr(link), template.HTMLEscapeString(title))
+	return err
+}
+
+const tocPlaceholder = "<!-- notion2md:toc -->"
+
+// tableOfContents 根据已渲染的标题生成目录
+func (p *BlockProcessor) tableOfContents() string {
+	var buf bytes.Buffer
+	buf.WriteString("<nav class=\"toc\">\n<ul>\n")
+	for _, h := range p.headings {
+		fmt.Fprintf(&buf, "<li class=\"toc-h%d\"><a href=\"#%s\">%s</a></li>\n", h.Level, attr(h.ID), template.HTMLEscapeString(h.Text))
+	}
+	buf.WriteString("</ul>\n</nav>")
+	return buf.String()
+}
+
+// SetPage 设置当前处理的页面，并清空上一页面的标题
+func (p *BlockProcessor) SetPage(page notionapi.Page) {
+	p.BlockProcessor.SetPage(page)
+	p.headings = nil
+}
+
+// richText 将富文本转换为转义后的 HTML
+func richText(text []notionapi.RichText) template.HTML {
+	var buf bytes.Buffer
+	for _, t := range text {
+		if t.Type != notionapi.ObjectTypeText || t.Text == nil {
+			// 提及等其他类型使用纯文本，有链接时保留链接
+			content := template.HTMLEscapeString(t.PlainText)
+			if t.Href != "" {
+				content = fmt.Sprintf("<a href=\"%s\">%s</a>", attr(t.Href), content)
+			}
+			buf.WriteString(content)
+			continue
+		}
+		content := strings.ReplaceAll(template.HTMLEscapeString(t.Text.Content), "\n", "<br>")
+		if a := t.Annotations; a != nil {
+			if a.Code {
+				content = "<code>" + content + "</code>"
+			}
+			if a.Bold {
+				content = "<strong>" + content + "</strong>"
+			}
+			if a.Italic {
+				content = "<em>" + content + "</em>"
+			}
+			if a.Strikethrough {
+				content = "<del>" + content + "</del>"
+			}
+			if a.Underline {
+				content = "<u>" + content + "</u>"
+			}
+		}
+		if t.Text.Link != nil {
+			content = fmt.Sprintf("<a href=\"%s\">%s</a>", attr(t.Text.Link.Url), content)
+		}
+		buf.WriteString(content)
+	}
+	return template.HTML(buf.String())
+}
+
+func plainText(text []notionapi.RichText) string {
+	var parts []string
+	for _, t := range text {
+		if t.Type == notionapi.ObjectTypeText && t.Text != nil {
+			parts = append(parts, t.Text.Content)
+		} else {
+			parts = append(parts, t.PlainText)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// attr 转义 HTML 属性值
+func attr(s string) string {
+	return template.HTMLEscapeString(s)
+}
+
+var headingPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+
+func headingID(text string) string {
+	return strings.Trim(headingPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
+
+func youTubeID(url string) string {
+	for _, marker := range []string{"youtu.be/", "watch?v=", "youtube.com/embed/"} {
+		if i := strings.Index(url, marker); i >= 0 {
+			fields := strings.FieldsFunc(url[i+len(marker):], func(r rune) bool { return r == '?' || r == '&' || r == '/' })
+			if len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
+
+// listTags 返回列表项所属列表的开始和结束标签
+func listTags(block notionapi.Block) (string, string) {
+	switch block.(type) {
+	case *notionapi.NumberedListItemBlock:
+		return "<ol>", "</ol>"
+	case *notionapi.ToDoBlock:
+		return "<ul class=\"todo\">", "</ul>"
+	}
+	return "<ul>", "</ul>"
+}
+
+func sameType(a, b notionapi.Block) bool {
+	return a != nil && b != nil && a.GetType() == b.GetType()
+}
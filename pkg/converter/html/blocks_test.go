@@ -0,0 +1,104 @@
+package html
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+var update = flag.Bool("update", false, "更新 testdata/golden 下的期望输出")
+
+// TestGolden 使用 Markdown 渲染共用的块树，检查 HTML 输出
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("..", "notion", "testdata", "blocks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("没有找到块树")
+	}
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			p := NewBlockProcessor(notion.NewBlockProcessor(nil, &config.Config{}))
+			var got bytes.Buffer
+			if err := p.ProcessBlocks(loadBlocks(t, fixture), &got); err != nil {
+				t.Fatalf("渲染失败: %v", err)
+			}
+			compareGolden(t, filepath.Join("testdata", "golden", name+".html"), got.Bytes())
+		})
+	}
+}
+
+func TestUnsupportedBlocks(t *testing.T) {
+	blocks := parseBlocks(t, `[{"object":"block","id":"audio-1","type":"audio","audio":{}}]`)
+
+	var config config.Config
+	p := NewBlockProcessor(notion.NewBlockProcessor(nil, &config))
+	var out bytes.Buffer
+	if err := p.ProcessBlocks(blocks, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("skip 模式不应输出内容，得到 %q", out.String())
+	}
+	if len(p.Skipped()) != 1 {
+		t.Errorf("应记录 1 个跳过的块，得到 %d", len(p.Skipped()))
+	}
+
+	config.Blocks.Strict = true
+	p = NewBlockProcessor(notion.NewBlockProcessor(nil, &config))
+	if err := p.ProcessBlocks(blocks, &out); !errors.Is(err, notion.ErrUnsupportedBlock) {
+		t.Errorf("严格模式应返回 ErrUnsupportedBlock，得到 %v", err)
+	}
+}
+
+func loadBlocks(t *testing.T, path string) notionapi.Blocks {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parseBlocks(t, string(data))
+}
+
+func parseBlocks(t *testing.T, data string) notionapi.Blocks {
+	t.Helper()
+	var blocks notionapi.Blocks
+	if err := json.Unmarshal([]byte(data), &blocks); err != nil {
+		t.Fatalf("解析块失败: %v", err)
+	}
+	return blocks
+}
+
+func compareGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("读取 golden 文件失败（使用 -update 生成）: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s 输出不一致\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+	}
+}
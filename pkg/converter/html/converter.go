@@ -0,0 +1,199 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/media"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+// pageTemplate 是默认的完整页面模板
+const pageTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ .Title }}</title>
+{{- with .Description }}
+<meta name="description" content="{{ . }}">
+{{- end }}
+</head>
+<body>
+{{ template "article" . }}
+</body>
+</html>
+`
+
+// articleTemplate 是默认的文章片段模板
+const articleTemplate = `{{ define "article" }}<article>
+<header>
+<h1>{{ .Title }}</h1>
+{{- with .Date }}
+<time datetime="{{ . }}">{{ . }}</time>
+{{- end }}
+{{- with .Author }}
+<address>{{ . }}</address>
+{{- end }}
+{{- with .Cover }}
+<figure><img src="{{ . }}" alt=""></figure>
+{{- end }}
+</header>
+{{ .Content }}
+{{- with .Tags }}
+<footer>
+<ul class="tags">
+{{- range . }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+</footer>
+{{- end }}
+</article>
+{{ end }}`
+
+// HTMLConverter 将 Notion 页面渲染为独立的 HTML 页面或片段
+type HTMLConverter struct {
+	outputPath     string
+	templatePath   string
+	fragment       bool
+	writer         converter.Writer
+	blockProcessor *BlockProcessor
+	metaProcessor  converter.MetadataProcessor
+}
+
+// New 创建 HTML 转换器，fragment 为 true 时只输出 <article> 片段
+func New(blockProcessor *BlockProcessor, metaProcessor converter.MetadataProcessor, fragment bool) *HTMLConverter {
+	return &HTMLConverter{
+		blockProcessor: blockProcessor,
+		metaProcessor:  metaProcessor,
+		fragment:       fragment,
+		writer:         converter.DiskWriter{},
+	}
+}
+
+func (h *HTMLConverter) Convert(page notionapi.Page, blocks []notionapi.Block) error {
+	// 处理元数据
+	metadata, err := h.metaProcessor.ProcessMetadata(page)
+	if err != nil {
+		return fmt.Errorf("处理元数据失败: %w", err)
+	}
+	if metadata == nil {
+		return converter.ErrSkipPage
+	}
+
+	category := site.Category(metadata)
+	slug := site.Slugify(page, metadata)
+
+	h.blockProcessor.SetPage(page)
+	if mediaHandler, ok := h.blockProcessor.GetMediaHandler().(media.ContextHandler); ok {
+		mediaHandler.SetContext(category, slug)
+	}
+
+	// 处理内容
+	var content bytes.Buffer
+	if err := h.blockProcessor.ProcessBlocks(blocks, &content); err != nil {
+		return fmt.Errorf("处理块失败: %w", err)
+	}
+	body := content.String()
+	if notion.HasBlockType(blocks, notionapi.BlockTypeTableOfContents) {
+		body = strings.ReplaceAll(body, tocPlaceholder, h.blockProcessor.tableOfContents())
+	}
+
+	// 报告未支持的块
+	for _, skipped := range h.blockProcessor.Skipped() {
+		slog.Warn("跳过未支持的块", "page_id", string(page.ID), "title", metadata["title"], "block_type", skipped.Type, "block_id", skipped.ID, "url", skipped.URL)
+	}
+
+	tmpl, err := h.template()
+	if err != nil {
+		return err
+	}
+
+	data := map[string]interface{}{
+		"Title":       metadata["title"],
+		"MetaTitle":   metadata["meta_title"],
+		"Description": metadata["description"],
+		"Date":        metadata["date"],
+		"Lastmod":     metadata["lastmod"],
+		"Author":      metadata["author"],
+		"Cover":       metadata["cover"],
+		"Tags":        metadata["tags"],
+		"Categories":  metadata["categories"],
+		"Draft":       metadata["draft"],
+		"Metadata":    metadata,
+		"Content":     template.HTML(body),
+	}
+
+	name := "page"
+	if h.fragment {
+		name = "article"
+	}
+	var output bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&output, name, data); err != nil {
+		return fmt.Errorf("渲染模板失败: %w", err)
+	}
+
+	// 写入输出文件
+	return h.writer.WriteFile(filepath.Join(h.outputPath, category, slug+".html"), output.Bytes())
+}
+
+func (h *HTMLConverter) Locate(page notionapi.Page) (string, string, error) {
+	metadata, err := h.metaProcessor.ProcessMetadata(page)
+	if err != nil {
+		return "", "", fmt.Errorf("处理元数据失败: %w", err)
+	}
+	if metadata == nil {
+		return "", "", converter.ErrSkipPage
+	}
+	category := site.Category(metadata)
+	slug := site.Slugify(page, metadata)
+	return filepath.Join(h.outputPath, category, slug+".html"), filepath.Join(category, slug), nil
+}
+
+// template 返回页面模板，html.template 配置的模板文件可以覆盖 page 和 article 两个模板
+func (h *HTMLConverter) template() (*template.Template, error) {
+	tmpl, err := template.New("page").Parse(pageTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmpl.Parse(articleTemplate); err != nil {
+		return nil, err
+	}
+	if h.templatePath == "" {
+		return tmpl, nil
+	}
+
+	custom, err := os.ReadFile(h.templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("读取模板失败: %w", err)
+	}
+	if _, err := tmpl.Parse(string(custom)); err != nil {
+		return nil, fmt.Errorf("解析模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+func (h *HTMLConverter) SetOutput(path string) error {
+	h.outputPath = path
+	return nil
+}
+
+// SetTemplate 设置 html.template 配置的 html/template 文件，为空时使用内置模板
+func (h *HTMLConverter) SetTemplate(template string) error {
+	h.templatePath = template
+	return nil
+}
+
+func (h *HTMLConverter) SetWriter(w converter.Writer) {
+	h.writer = w
+}
@@ -0,0 +1,80 @@
+package html
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+type staticMetadata map[string]interface{}
+
+func (m staticMetadata) ProcessMetadata(page notionapi.Page) (map[string]interface{}, error) {
+	return m, nil
+}
+
+func TestConvert(t *testing.T) {
+	blocks := parseBlocks(t, `[
+		{"object":"block","id":"toc","type":"table_of_contents","table_of_contents":{}},
+		{"object":"block","id":"h","type":"heading_1","heading_1":{"rich_text":[{"type":"text","text":{"content":"Intro & more"},"annotations":{}}]}}
+	]`)
+	metadata := staticMetadata{"title": "A <title>", "category_dir": "notes"}
+
+	tests := []struct {
+		name     string
+		fragment bool
+		template string
+		want     []string
+	}{
+		{
+			name: "page",
+			want: []string{"<!DOCTYPE html>", "<title>A &lt;title&gt;</title>", `<a href="#intro-more">Intro &amp; more</a>`},
+		},
+		{
+			name:     "fragment",
+			fragment: true,
+			want:     []string{"<article>", `<h2 id="intro-more">`},
+		},
+		{
+			name:     "custom article",
+			template: `{{ define "article" }}<main>{{ .Title }}</main>{{ end }}`,
+			want:     []string{"<!DOCTYPE html>", "<main>A &lt;title&gt;</main>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			conv := New(NewBlockProcessor(notion.NewBlockProcessor(nil, &config.Config{})), metadata, tt.fragment)
+			conv.SetOutput(dir)
+			if tt.template != "" {
+				path := filepath.Join(dir, "page.html.tmpl")
+				if err := os.WriteFile(path, []byte(tt.template), 0644); err != nil {
+					t.Fatal(err)
+				}
+				conv.SetTemplate(path)
+			}
+
+			if err := conv.Convert(notionapi.Page{ID: "page-1"}, blocks); err != nil {
+				t.Fatal(err)
+			}
+			out, err := os.ReadFile(filepath.Join(dir, "notes", "page-1.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("输出中缺少 %q:\n%s", want, out)
+				}
+			}
+			if tt.fragment && strings.Contains(string(out), "<html") {
+				t.Errorf("片段模式不应输出完整页面:\n%s", out)
+			}
+		})
+	}
+}
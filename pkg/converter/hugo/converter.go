@@ -3,29 +3,30 @@ package hugo
 import (
 	"bytes"
 	"fmt"
-	"os"
+	"log/slog"
 	"path/filepath"
-	"regexp"
 	"strings"
 	"text/template"
 
 	"notion2md/pkg/converter"
 	"notion2md/pkg/converter/media"
 	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
 
 	"github.com/jomei/notionapi"
-	"github.com/mozillazg/go-pinyin"
 )
 
 type HugoConverter struct {
 	outputPath     string
 	templatePath   string
+	writer         converter.Writer
 	blockProcessor converter.BlockProcessor
 	metaProcessor  converter.MetadataProcessor
 }
 
 func New(blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *HugoConverter {
 	return &HugoConverter{
+		writer:         converter.DiskWriter{},
 		blockProcessor: blockProcessor,
 		metaProcessor:  metaProcessor,
 	}
@@ -37,29 +38,36 @@ func (h *HugoConverter) Convert(page notionapi.Page, blocks []notionapi.Block) e
 	if err != nil {
 		return fmt.Errorf("处理元数据失败: %w", err)
 	}
-
-	// 获取第一个分类作为目录
-	category := "uncategorized"
-	if categoryDir, ok := metadata["category_dir"].(string); ok {
-		category = categoryDir
-	} else if categories, ok := metadata["categories"].([]string); ok && len(categories) > 0 {
-		category = categories[0]
+	// 未配置分类映射的文章不生成文件
+	if metadata == nil {
+		return converter.ErrSkipPage
 	}
 
-	articleDir := h.generateFilename(page, metadata)
-	articleDir = strings.TrimSuffix(articleDir, ".md")
+	category, filename := h.articlePath(page, metadata)
+	articleDir := strings.TrimSuffix(filename, ".md")
 
 	if handler, ok := h.blockProcessor.(*notion.BlockProcessor); ok {
-		if mediaHandler, ok := handler.GetMediaHandler().(*media.LocalHandler); ok {
+		handler.SetPage(page)
+		if mediaHandler, ok := handler.GetMediaHandler().(media.ContextHandler); ok {
 			mediaHandler.SetContext(category, articleDir)
 		}
 	}
 
+	// 页面中包含目录块时开启 toc
+	if metadata != nil && notion.HasBlockType(blocks, notionapi.BlockTypeTableOfContents) {
+		metadata["toc"] = true
+	}
+
 	// 处理内容
 	var content bytes.Buffer
-	for _, block := range blocks {
-		if err := h.blockProcessor.ProcessBlock(block, &content); err != nil {
-			return fmt.Errorf("处理块失败: %w", err)
+	if err := h.blockProcessor.ProcessBlocks(blocks, &content); err != nil {
+		return fmt.Errorf("处理块失败: %w", err)
+	}
+
+	// 报告未支持的块
+	if handler, ok := h.blockProcessor.(*notion.BlockProcessor); ok {
+		for _, skipped := range handler.Skipped() {
+			slog.Warn("跳过未支持的块", "page_id", string(page.ID), "title", getOrDefault(metadata, "title", page.ID), "block_type", skipped.Type, "block_id", skipped.ID, "url", skipped.URL)
 		}
 	}
 
@@ -84,6 +92,8 @@ func (h *HugoConverter) Convert(page notionapi.Page, blocks []notionapi.Block) e
 		"Comments":    getOrDefault(metadata, "comments", false),
 		"Slug":        getOrDefault(metadata, "slug", ""),
 		"Lastmod":     getOrDefault(metadata, "lastmod", ""),
+		"PublishDate": getOrDefault(metadata, "publish_date", ""),
+		"ExpiryDate":  getOrDefault(metadata, "expiry_date", ""),
 	}
 
 	// 特殊处理标签
@@ -108,25 +118,13 @@ func (h *HugoConverter) Convert(page notionapi.Page, blocks []notionapi.Block) e
 		data["Categories"] = "[]"
 	}
 
-	// 创建输出文件
-	filename := h.generateFilename(page, metadata)
-	outputFile := filepath.Join(h.outputPath, category, filename)
-
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
-		return fmt.Errorf("创建目录失败: %w", err)
-	}
-
-	f, err := os.Create(outputFile)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %w", err)
-	}
-	defer f.Close()
-
-	if err := tmpl.Execute(f, data); err != nil {
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, data); err != nil {
 		return fmt.Errorf("渲染模板失败: %w", err)
 	}
 
-	return nil
+	// 写入输出文件
+	return h.writer.WriteFile(filepath.Join(h.outputPath, category, filename), output.Bytes())
 }
 
 func (h *HugoConverter) SetOutput(path string) error {
@@ -139,30 +137,30 @@ func (h *HugoConverter) SetTemplate(template string) error {
 	return nil
 }
 
-func (h *HugoConverter) generateFilename(page notionapi.Page, metadata map[string]interface{}) string {
-	// 获取标题，如果为空则使用 ID
-	title, ok := metadata["title"].(string)
-	if !ok || title == "" {
-		return string(page.ID) + ".md"
-	}
-
-	// 转换为拼音
-	args := pinyin.NewArgs()
-	args.Separator = "-" // 分隔符
-	pys := pinyin.LazyPinyin(title, args)
-	filename := strings.Join(pys, "-")
-
-	// 清理文件名
-	reg := regexp.MustCompile(`[^a-z0-9-]+`)
-	filename = reg.ReplaceAllString(filename, "-")
-	filename = strings.Trim(filename, "-")
+func (h *HugoConverter) SetWriter(w converter.Writer) {
+	h.writer = w
+}
 
-	// 如果清理后文件名为空，使用 ID
-	if filename == "" {
-		filename = string(page.ID)
+func (h *HugoConverter) Locate(page notionapi.Page) (string, string, error) {
+	metadata, err := h.metaProcessor.ProcessMetadata(page)
+	if err != nil {
+		return "", "", fmt.Errorf("处理元数据失败: %w", err)
+	}
+	if metadata == nil {
+		return "", "", converter.ErrSkipPage
 	}
+	category, filename := h.articlePath(page, metadata)
+	return filepath.Join(h.outputPath, category, filename), filepath.Join(category, strings.TrimSuffix(filename, ".md")), nil
+}
+
+// articlePath 返回文章所在的分类目录和文件名
+func (h *HugoConverter) articlePath(page notionapi.Page, metadata map[string]interface{}) (string, string) {
+	return site.Category(metadata), articleFilename(page, metadata)
+}
 
-	return filename + ".md"
+// articleFilename 返回文章的文件名
+func articleFilename(page notionapi.Page, metadata map[string]interface{}) string {
+	return site.Slugify(page, metadata) + ".md"
 }
 
 func getOrDefault(m map[string]interface{}, key string, defaultValue interface{}) interface{} {
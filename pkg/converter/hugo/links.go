@@ -0,0 +1,143 @@
+package hugo
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+// LinkResolver 通过 Notion API 获取被链接的页面，并解析为 Hugo 站内链接
+//
+// 被链接的页面可以属于任意一个来源的数据库，按该来源的分类映射、状态和内容目录生成链接。
+type LinkResolver struct {
+	ctx     context.Context
+	client  *notionapi.Client
+	current *linkSource
+	sources []*linkSource
+	cache   map[notionapi.PageID][2]string
+}
+
+// linkSource 是解析链接时需要的来源配置
+type linkSource struct {
+	databaseID     string
+	folder         string
+	statusProperty string
+	status         []string
+	metaProcessor  *notion.MetadataProcessor
+}
+
+func newLinkSource(config *config.Config) *linkSource {
+	return &linkSource{
+		databaseID:     notion.NormalizeID(config.DatabaseID),
+		folder:         filepath.Clean(config.Content.Folder),
+		statusProperty: notion.StatusProperty(config),
+		// 只有待发布和已发布的文章会出现在站点中
+		status:        []string{config.Notion.Status.Ready, config.Notion.Status.Published},
+		metaProcessor: notion.NewMetadataProcessor(config),
+	}
+}
+
+// NewLinkResolver 创建链接解析器，current 为正在转换的来源，sources 为所有来源
+func NewLinkResolver(ctx context.Context, client *notionapi.Client, current *config.Config, sources []*config.Config) *LinkResolver {
+	r := &LinkResolver{
+		ctx:     ctx,
+		client:  client,
+		current: newLinkSource(current),
+		cache:   make(map[notionapi.PageID][2]string),
+	}
+	for _, source := range sources {
+		r.sources = append(r.sources, newLinkSource(source))
+	}
+	if len(r.sources) == 0 {
+		r.sources = []*linkSource{r.current}
+	}
+	return r
+}
+
+func (r *LinkResolver) ResolvePage(pageID notionapi.PageID) (string, string, error) {
+	if cached, ok := r.cache[pageID]; ok {
+		return cached[0], cached[1], nil
+	}
+
+	page, err := r.client.Page.Get(r.ctx, pageID)
+	if err != nil {
+		return "", "", fmt.Errorf("获取页面失败: %w", err)
+	}
+
+	// 不属于任何来源或未发布的页面不会生成文章，链接到 Notion 原页面
+	title := page.URL
+	link := page.URL
+	if source := r.publishedSource(page); source != nil {
+		metadata, err := source.metaProcessor.ProcessMetadata(*page)
+		if err != nil {
+			return "", "", fmt.Errorf("处理元数据失败: %w", err)
+		}
+		// 未配置分类映射的页面同样不会生成文章
+		if metadata != nil {
+			title, _ = getOrDefault(metadata, "title", page.URL).(string)
+			link = fmt.Sprintf("{{< ref %q >}}", r.refPath(source, *page, metadata))
+		}
+	}
+
+	r.cache[pageID] = [2]string{title, link}
+	return title, link, nil
+}
+
+// refPath 返回 ref 短代码中的文章路径
+//
+// 与当前来源在同一内容目录中的文章使用 分类/文件名；其他来源的文章使用从 Hugo 的 content 目录开始的
+// 绝对路径，内容目录不在 content 目录中时无法确定，仍使用 分类/文件名。
+func (r *LinkResolver) refPath(source *linkSource, page notionapi.Page, metadata map[string]interface{}) string {
+	rel := path.Join(site.Category(metadata), articleFilename(page, metadata))
+	if source.folder == r.current.folder {
+		return rel
+	}
+	if dir, ok := contentPath(source.folder); ok {
+		return "/" + path.Join(dir, rel)
+	}
+	return rel
+}
+
+// contentPath 返回内容目录相对 Hugo content 目录的路径，例如 site/content/notes 返回 notes
+func contentPath(folder string) (string, bool) {
+	parts := strings.Split(filepath.ToSlash(folder), "/")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "content" {
+			return strings.Join(parts[i+1:], "/"), true
+		}
+	}
+	return "", false
+}
+
+// publishedSource 返回页面所属的来源，页面不属于任何来源的数据库或不处于待发布、已发布状态时返回 nil
+func (r *LinkResolver) publishedSource(page *notionapi.Page) *linkSource {
+	databaseID := notion.NormalizeID(string(page.Parent.DatabaseID))
+	for _, source := range r.sources {
+		if source.databaseID == databaseID && source.published(page) {
+			return source
+		}
+	}
+	return nil
+}
+
+// published 判断页面是否处于待发布或已发布状态
+func (s *linkSource) published(page *notionapi.Page) bool {
+	status := notion.PageStatus(*page, s.statusProperty)
+	if status == "" {
+		return false
+	}
+	for _, name := range s.status {
+		if name != "" && status == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package hugo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"notion2md/pkg/config"
+	"notion2md/pkg/fixture"
+
+	"github.com/jomei/notionapi"
+)
+
+func testPage(id, title, status, category string) string {
+	return fmt.Sprintf(`{
+		"id": %q,
+		"url": "https://www.notion.so/%s",
+		"created_time": "2024-01-01T00:00:00Z",
+		"last_edited_time": "2024-01-02T00:00:00Z",
+		"properties": {
+			"Name": {"id": "title", "type": "title", "title": [{"type": "text", "text": {"content": %q}, "plain_text": %q}]},
+			"Status": {"id": "s", "type": "status", "status": {"name": %q}},
+			"Category": {"id": "c", "type": "select", "select": {"name": %q}}
+		}
+	}`, id, id, title, title, status, category)
+}
+
+func testSource(databaseID, folder, published string, categoryMap map[string]string) *config.Config {
+	var c config.Config
+	c.DatabaseID = databaseID
+	c.Content.Folder = folder
+	c.Notion.CategoryMap = categoryMap
+	c.Notion.Status.Ready = "Ready"
+	c.Notion.Status.Published = published
+	return &c
+}
+
+func TestLinkResolverSources(t *testing.T) {
+	server := fixture.NewServer()
+	defer server.Close()
+	server.AddPage("posts-db", testPage("post", "你好", "Published", "技术"))
+	server.AddPage("notes-db", testPage("note", "笔记", "Done", "读书"))
+	server.AddPage("notes-db", testPage("draft", "草稿", "Draft", "读书"))
+	server.AddPage("other-db", testPage("other", "其他", "Published", "技术"))
+
+	posts := testSource("posts-db", "site/content/posts", "Published", map[string]string{"技术": "tech"})
+	// 另一个来源使用自己的分类映射、状态取值和内容目录
+	notes := testSource("notes-db", "site/content/notes", "Done", map[string]string{"读书": "reading"})
+
+	client := notionapi.NewClient("test-token", notionapi.WithHTTPClient(&http.Client{Transport: server.Transport()}))
+	r := NewLinkResolver(context.Background(), client, posts, []*config.Config{posts, notes})
+
+	for _, tt := range []struct {
+		id    string
+		title string
+		link  string
+	}{
+		{"post", "你好", `{{< ref "tech/ni-hao.md" >}}`},
+		{"note", "笔记", `{{< ref "/notes/reading/bi-ji.md" >}}`},
+		// 未发布或不属于任何来源的页面链接到 Notion
+		{"draft", "https://www.notion.so/draft", "https://www.notion.so/draft"},
+		{"other", "https://www.notion.so/other", "https://www.notion.so/other"},
+	} {
+		title, link, err := r.ResolvePage(notionapi.PageID(tt.id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if title != tt.title || link != tt.link {
+			t.Errorf("%s 解析为 %q %q，期望 %q %q", tt.id, title, link, tt.title, tt.link)
+		}
+	}
+}
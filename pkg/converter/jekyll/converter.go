@@ -0,0 +1,51 @@
+package jekyll
+
+import (
+	"fmt"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+)
+
+// Dialect 实现 Jekyll 的约定：文章位于 _posts，文件名以日期开头
+type Dialect struct{}
+
+func New(blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *site.Converter {
+	return site.New(Dialect{}, blockProcessor, metaProcessor)
+}
+
+func (Dialect) Shortcodes() notion.Shortcodes {
+	// kramdown 的目录标记
+	return notion.Shortcodes{
+		TableOfContents: "* TOC\n{:toc}",
+	}
+}
+
+func (Dialect) FrontMatter(a *site.Article) string {
+	var f site.Fields
+	f.Add("layout", "post")
+	f.Add("title", a.String("title"))
+	f.Add("date", a.Date)
+	f.Add("last_modified_at", a.Time("lastmod"))
+	f.Add("categories", a.Strings("categories"))
+	f.Add("tags", a.Strings("tags"))
+	f.Add("description", a.String("description"))
+	f.Add("author", a.String("author"))
+	f.Add("image", a.String("cover"))
+	if a.Bool("draft") {
+		f.Add("published", false)
+	}
+	if a.Bool("toc") {
+		f.Add("toc", true)
+	}
+	if a.Bool("comments") {
+		f.Add("comments", true)
+	}
+	f.Add("slug", a.String("slug"))
+	return site.YAML(f)
+}
+
+func (Dialect) Path(a *site.Article) string {
+	return fmt.Sprintf("%s-%s.md", a.Date.Format("2006-01-02"), a.Slug)
+}
@@ -0,0 +1,64 @@
+package jekyll
+
+import (
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDialect(t *testing.T) {
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name        string
+		metadata    map[string]interface{}
+		path        string
+		frontMatter string
+	}{
+		{
+			name: "文章",
+			metadata: map[string]interface{}{
+				"title":      "你好",
+				"lastmod":    "2024-05-02T09:30:00Z",
+				"categories": []string{"技术"},
+				"tags":       []string{"Go", "Hugo"},
+				"toc":        true,
+				"slug":       "hello",
+			},
+			path: "2024-05-01-ni-hao.md",
+			frontMatter: `---
+layout: "post"
+title: "你好"
+date: 2024-05-01 08:00:00 +0000
+last_modified_at: 2024-05-02 09:30:00 +0000
+categories: ["技术"]
+tags: ["Go", "Hugo"]
+toc: true
+slug: "hello"
+---
+`,
+		},
+		{
+			name:     "草稿",
+			metadata: map[string]interface{}{"title": "草稿", "draft": true, "comments": false},
+			path:     "2024-05-01-cao-gao.md",
+			frontMatter: `---
+layout: "post"
+title: "草稿"
+date: 2024-05-01 08:00:00 +0000
+published: false
+---
+`,
+		},
+	} {
+		a := &site.Article{Metadata: tt.metadata, Slug: site.Slugify(notionapi.Page{}, tt.metadata), Category: site.Category(tt.metadata), Date: date}
+		if got := (Dialect{}).Path(a); got != tt.path {
+			t.Errorf("%s 的路径为 %q，期望 %q", tt.name, got, tt.path)
+		}
+		if got := (Dialect{}).FrontMatter(a); got != tt.frontMatter {
+			t.Errorf("%s 的 front matter 为:\n%s\n期望:\n%s", tt.name, got, tt.frontMatter)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package media
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,19 +11,27 @@ import (
 )
 
 type LocalHandler struct {
+	ctx       context.Context
 	savePath  string
 	urlPrefix string
 	category  string
 	article   string
 }
 
-func NewLocalHandler(savePath, urlPrefix string) *LocalHandler {
+// NewLocalHandler 创建本地媒体处理器，ctx 取消时正在进行的下载会被中断
+func NewLocalHandler(ctx context.Context, savePath, urlPrefix string) *LocalHandler {
 	return &LocalHandler{
+		ctx:       ctx,
 		savePath:  savePath,
 		urlPrefix: urlPrefix,
 	}
 }
 
+// ContextHandler 由按文章目录保存媒体的处理器实现，转换每篇文章前设置分类和文章目录
+type ContextHandler interface {
+	SetContext(category, article string)
+}
+
 // 设置当前处理的文章信息
 func (h *LocalHandler) SetContext(category, article string) {
 	h.category = category
@@ -30,40 +39,21 @@ func (h *LocalHandler) SetContext(category, article string) {
 }
 
 func (h *LocalHandler) SaveMedia(url string) (string, error) {
-	// 清理 URL 中的查询参数
-	cleanURL := strings.Split(url, "?")[0]
-
 	// 下载文件
-	resp, err := http.Get(url)
+	resp, err := download(h.ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("下载文件失败: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 生成文件名
-	filename := filepath.Base(cleanURL)
-	if filename == "" || filepath.Ext(filename) == "" {
-		contentType := resp.Header.Get("Content-Type")
-		ext := ".bin"
-		switch contentType {
-		case "image/jpeg":
-			ext = ".jpg"
-		case "image/png":
-			ext = ".png"
-		case "image/gif":
-			ext = ".gif"
-		case "video/mp4":
-			ext = ".mp4"
-		}
-		filename = fmt.Sprintf("image-%d%s", len(filename), ext)
+	filename, ok := fileName(url)
+	if !ok {
+		filename = fmt.Sprintf("image-%d%s", len(filename), extension(resp.Header.Get("Content-Type")))
 	}
 
 	// 构建保存路径
-	relativePath := filename
-	if h.category != "" && h.article != "" {
-		relativePath = filepath.Join(h.category, h.article, filename)
-	}
-
+	relativePath := localPath(h.category, h.article, filename)
 	fullPath := filepath.Join(h.savePath, relativePath)
 
 	// 创建目录
@@ -84,7 +74,47 @@ func (h *LocalHandler) SaveMedia(url string) (string, error) {
 	}
 
 	// 返回相对 URL
-	return h.urlPrefix + "/" + strings.ReplaceAll(relativePath, "\\", "/"), nil
+	return h.urlPrefix + "/" + filepath.ToSlash(relativePath), nil
+}
+
+// LocalURL 返回本地处理器保存 url 后返回的链接，不下载文件
+//
+// 链接中没有文件扩展名时文件名取决于响应的 Content-Type，此时 ok 为 false。
+func LocalURL(urlPrefix, category, article, url string) (link string, ok bool) {
+	filename, ok := fileName(url)
+	if !ok {
+		return "", false
+	}
+	return urlPrefix + "/" + filepath.ToSlash(localPath(category, article, filename)), true
+}
+
+// localPath 返回媒体文件相对保存目录的路径，设置了文章信息时保存在 分类/文章 目录中
+func localPath(category, article, filename string) string {
+	if category != "" && article != "" {
+		return filepath.Join(category, article, filename)
+	}
+	return filename
+}
+
+// fileName 返回链接中去掉查询参数后的文件名，没有扩展名时 ok 为 false
+func fileName(url string) (string, bool) {
+	filename := filepath.Base(strings.Split(url, "?")[0])
+	return filename, filename != "" && filepath.Ext(filename) != ""
+}
+
+// extension 根据 Content-Type 返回文件扩展名，未知类型为 .bin
+func extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "video/mp4":
+		return ".mp4"
+	}
+	return ".bin"
 }
 
 func (h *LocalHandler) SupportedTypes() []string {
@@ -100,3 +130,20 @@ func (h *LocalHandler) SupportedTypes() []string {
 		"application/pdf",
 	}
 }
+
+// download 使用 ctx 发起 GET 请求，非 2xx 响应视为错误
+func download(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return resp, nil
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,20 +15,30 @@ import (
 )
 
 type S3Handler struct {
+	ctx        context.Context
 	client     *s3.Client
 	bucket     string
 	pathPrefix string
 	urlPrefix  string
 }
 
-func NewS3Handler(bucket, region, pathPrefix, urlPrefix string) (*S3Handler, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+// NewS3Handler 创建 S3 媒体处理器，ctx 取消时正在进行的下载和上传会被中断
+//
+// endpoint 不为空时连接该地址而不是 AWS，forcePathStyle 为 true 时使用路径风格的地址。
+func NewS3Handler(ctx context.Context, bucket, region, pathPrefix, urlPrefix, endpoint string, forcePathStyle bool) (*S3Handler, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
 	if err != nil {
 		return nil, fmt.Errorf("无法加载 AWS 配置: %w", err)
 	}
 
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = forcePathStyle
+	})
 	return &S3Handler{
+		ctx:        ctx,
 		client:     client,
 		bucket:     bucket,
 		pathPrefix: pathPrefix,
@@ -39,7 +48,7 @@ func NewS3Handler(bucket, region, pathPrefix, urlPrefix string) (*S3Handler, err
 
 func (h *S3Handler) SaveMedia(url string) (string, error) {
 	// 下载文件
-	resp, err := http.Get(url)
+	resp, err := download(h.ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("下载文件失败: %w", err)
 	}
@@ -51,21 +60,10 @@ func (h *S3Handler) SaveMedia(url string) (string, error) {
 		return "", fmt.Errorf("读取内容失败: %w", err)
 	}
 
-	// 生成文件名
-	filename := filepath.Base(url)
-	if filename == "" || !strings.Contains(filename, ".") {
-		ext := ".bin"
-		switch resp.Header.Get("Content-Type") {
-		case "image/jpeg":
-			ext = ".jpg"
-		case "image/png":
-			ext = ".png"
-		case "image/gif":
-			ext = ".gif"
-		case "video/mp4":
-			ext = ".mp4"
-		}
-		filename = fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
+	// 生成文件名，去掉 Notion 签名链接中的查询参数，同一个文件每次同步的对象名相同
+	filename, ok := fileName(url)
+	if !ok {
+		filename = fmt.Sprintf("%d%s", time.Now().UnixNano(), extension(resp.Header.Get("Content-Type")))
 	}
 
 	// 构建 S3 路径
@@ -75,7 +73,7 @@ func (h *S3Handler) SaveMedia(url string) (string, error) {
 	}
 
 	// 上传到 S3
-	_, err = h.client.PutObject(context.TODO(), &s3.PutObjectInput{
+	_, err = h.client.PutObject(h.ctx, &s3.PutObjectInput{
 		Bucket:      aws.String(h.bucket),
 		Key:         aws.String(s3Path),
 		Body:        bytes.NewReader(content),
@@ -89,6 +87,43 @@ func (h *S3Handler) SaveMedia(url string) (string, error) {
 	return h.urlPrefix + "/" + filename, nil
 }
 
+// S3URL 返回 S3 处理器上传 url 后返回的链接，不下载也不上传
+//
+// 链接中没有文件扩展名时对象名按上传时间生成，此时 ok 为 false。
+func S3URL(urlPrefix, url string) (link string, ok bool) {
+	filename, ok := fileName(url)
+	if !ok {
+		return "", false
+	}
+	return urlPrefix + "/" + filename, true
+}
+
+// Check 上传并删除一个测试对象，确认存储桶存在且凭证有写入和删除权限
+func (h *S3Handler) Check() error {
+	key := filepath.Join(h.pathPrefix, fmt.Sprintf(".notion2md-check-%d", time.Now().UnixNano()))
+	if !strings.HasPrefix(key, "/") {
+		key = "/" + key
+	}
+
+	_, err := h.client.PutObject(h.ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(h.bucket),
+		Key:         aws.String(key),
+		Body:        strings.NewReader("notion2md"),
+		ContentType: aws.String("text/plain"),
+	})
+	if err != nil {
+		return fmt.Errorf("上传测试对象 %s 失败: %w", key, err)
+	}
+	_, err = h.client.DeleteObject(h.ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(h.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("删除测试对象 %s 失败: %w", key, err)
+	}
+	return nil
+}
+
 func (h *S3Handler) SupportedTypes() []string {
 	return []string{
 		"image/jpeg",
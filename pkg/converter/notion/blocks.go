@@ -2,96 +2,199 @@ package notion
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"html"
 	"io"
+	"net/url"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
+	"notion2md/pkg/config"
 	"notion2md/pkg/converter"
 
 	"github.com/jomei/notionapi"
 )
 
+// 输出的 Markdown 风格
+const (
+	FlavorHugo       = config.FlavorHugo
+	FlavorGFM        = config.FlavorGFM
+	FlavorCommonMark = config.FlavorCommonMark
+)
+
+// ErrUnsupportedBlock 表示严格模式下遇到了未支持的块
+var ErrUnsupportedBlock = errors.New("不支持的块类型")
+
 type BlockProcessor struct {
 	mediaHandler converter.MediaHandler
+	linkResolver converter.LinkResolver
 	codeStyle    string
-	config       struct {
-		UseShortcodes bool
-		Image         struct {
-			MaxWidth int
-			Quality  int
-			Formats  []string
-		}
-	}
+	shortcodes   Shortcodes
+	renderers    map[notionapi.BlockType]BlockRenderer
+	listNumbers  []int
+	pageURL      string
+	skipped      []converter.SkippedBlock
+	// flavor 为输出的 Markdown 风格，useShortcodes 表示是否输出目标的短代码
+	flavor        string
+	useShortcodes bool
+	blocks        config.BlocksConfig
+	code          config.CodeConfig
 }
 
-func NewBlockProcessor(mediaHandler converter.MediaHandler, config *converter.Config) *BlockProcessor {
-	return &BlockProcessor{
+func NewBlockProcessor(mediaHandler converter.MediaHandler, config *config.Config) *BlockProcessor {
+	p := &BlockProcessor{
 		mediaHandler: mediaHandler,
 		codeStyle:    "github",
-		config: struct {
-			UseShortcodes bool
-			Image         struct {
-				MaxWidth int
-				Quality  int
-				Formats  []string
-			}
-		}{
-			UseShortcodes: true,
-			Image: struct {
-				MaxWidth int
-				Quality  int
-				Formats  []string
-			}{
-				MaxWidth: config.Image.MaxWidth,
-				Quality:  config.Image.Quality,
-				Formats:  config.Image.Formats,
-			},
-		},
+		shortcodes:   HugoShortcodes,
+		renderers:    make(map[notionapi.BlockType]BlockRenderer),
+	}
+	p.flavor = config.Flavor
+	if p.flavor == "" {
+		p.flavor = FlavorHugo
 	}
+	p.useShortcodes = p.flavor == FlavorHugo
+	p.blocks = config.Blocks
+	p.code = config.Code
+	p.code.Languages = normalizeLanguages(config.Code.Languages)
+
+	p.registerBuiltins()
+	return p
 }
 
-func (p *BlockProcessor) ProcessBlock(block notionapi.Block, w io.Writer) error {
-	switch b := block.(type) {
-	case *notionapi.Heading1Block:
+// registerBuiltins 注册内置的块渲染器
+func (p *BlockProcessor) registerBuiltins() {
+	p.Register(notionapi.BlockTypeHeading1, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.Heading1Block) error {
 		return p.processHeading(w, b.Heading1.RichText, 1)
-	case *notionapi.Heading2Block:
+	}))
+	p.Register(notionapi.BlockTypeHeading2, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.Heading2Block) error {
 		return p.processHeading(w, b.Heading2.RichText, 2)
-	case *notionapi.Heading3Block:
+	}))
+	p.Register(notionapi.BlockTypeHeading3, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.Heading3Block) error {
 		return p.processHeading(w, b.Heading3.RichText, 3)
-	case *notionapi.ParagraphBlock:
-		return p.processParagraph(w, b)
-	case *notionapi.BulletedListItemBlock:
-		return p.processBulletList(w, b)
-	case *notionapi.NumberedListItemBlock:
-		return p.processNumberedList(w, b)
-	case *notionapi.ToDoBlock:
-		return p.processTodo(w, b)
-	case *notionapi.ToggleBlock:
-		return p.processToggle(w, b)
-	case *notionapi.QuoteBlock:
-		return p.processQuote(w, b)
-	case *notionapi.CodeBlock:
-		return p.processCode(w, b)
-	case *notionapi.CalloutBlock:
-		return p.processCallout(w, b)
-	case *notionapi.ImageBlock:
-		return p.processImage(w, b)
-	case *notionapi.VideoBlock:
-		return p.processVideo(w, b)
-	case *notionapi.FileBlock:
-		return p.processFile(w, b)
-	case *notionapi.BookmarkBlock:
-		return p.processBookmark(w, b)
-	case *notionapi.EquationBlock:
-		return p.processEquation(w, b)
-	case *notionapi.DividerBlock:
-		_, err := fmt.Fprintln(w, "---")
+	}))
+	p.Register(notionapi.BlockTypeParagraph, RendererFor(p.processParagraph))
+	p.Register(notionapi.BlockTypeBulletedListItem, RendererFor(p.processBulletList))
+	p.Register(notionapi.BlockTypeNumberedListItem, RendererFor(p.processNumberedList))
+	p.Register(notionapi.BlockTypeToDo, RendererFor(p.processTodo))
+	p.Register(notionapi.BlockTypeToggle, RendererFor(p.processToggle))
+	p.Register(notionapi.BlockTypeQuote, RendererFor(p.processQuote))
+	p.Register(notionapi.BlockTypeCode, RendererFor(p.processCode))
+	p.Register(notionapi.BlockTypeCallout, RendererFor(p.processCallout))
+	p.Register(notionapi.BlockTypeImage, RendererFor(p.processImage))
+	p.Register(notionapi.BlockTypeVideo, RendererFor(p.processVideo))
+	p.Register(notionapi.BlockTypeFile, RendererFor(p.processFile))
+	p.Register(notionapi.BlockTypeBookmark, RendererFor(p.processBookmark))
+	p.Register(notionapi.BlockTypeEquation, RendererFor(p.processEquation))
+	p.Register(notionapi.BlockTypeDivider, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.DividerBlock) error {
+		_, err := fmt.Fprint(w, "---\n\n")
+		return err
+	}))
+	p.Register(notionapi.BlockTypeTableBlock, RendererFor(p.processTable))
+	p.Register(notionapi.BlockTypeColumnList, RendererFor(p.processColumns))
+	p.Register(notionapi.BlockTypeTableOfContents, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.TableOfContentsBlock) error {
+		// 目录标记依赖站点生成器，可移植的 Markdown 不输出
+		if !p.useShortcodes {
+			return nil
+		}
+		marker := p.blocks.TableOfContents
+		if marker == "" {
+			marker = p.shortcodes.TableOfContents
+		}
+		if marker == "" {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "%s\n\n", marker)
+		return err
+	}))
+	p.Register(notionapi.BlockTypeBreadcrumb, RendererFor(func(ctx *RenderContext, w io.Writer, b *notionapi.BreadcrumbBlock) error {
+		if p.blocks.Breadcrumb == "" {
+			return nil
+		}
+		_, err := fmt.Fprintf(w, "%s\n\n", p.blocks.Breadcrumb)
+		return err
+	}))
+	p.Register(notionapi.BlockTypeLinkToPage, RendererFor(p.processLinkToPage))
+}
+
+func (p *BlockProcessor) ProcessBlock(block notionapi.Block, w io.Writer) error {
+	return p.render(p.rootContext(), block, w)
+}
+
+// ProcessBlocks 渲染同级的块，渲染器可以通过 RenderContext 的 Prev 和 Next 访问相邻块
+func (p *BlockProcessor) ProcessBlocks(blocks []notionapi.Block, w io.Writer) error {
+	return p.renderBlocks(p.rootContext(), blocks, w)
+}
+
+func (p *BlockProcessor) rootContext() RenderContext {
+	return RenderContext{
+		MediaHandler: p.mediaHandler,
+		LinkResolver: p.linkResolver,
+		processor:    p,
+	}
+}
+
+// renderBlocks 依次渲染同级的块，并设置每个块的相邻块
+func (p *BlockProcessor) renderBlocks(ctx RenderContext, blocks []notionapi.Block, w io.Writer) error {
+	for i, block := range blocks {
+		ctx.Prev, ctx.Next = nil, nil
+		if i > 0 {
+			ctx.Prev = blocks[i-1]
+		}
+		if i+1 < len(blocks) {
+			ctx.Next = blocks[i+1]
+		}
+		if err := p.render(ctx, block, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render 使用注册的渲染器渲染单个块，并维护有序列表的序号
+func (p *BlockProcessor) render(ctx RenderContext, block notionapi.Block, w io.Writer) error {
+	for len(p.listNumbers) <= ctx.Depth {
+		p.listNumbers = append(p.listNumbers, 0)
+	}
+	if block.GetType() == notionapi.BlockTypeNumberedListItem {
+		p.listNumbers[ctx.Depth]++
+	} else {
+		p.listNumbers[ctx.Depth] = 0
+	}
+	ctx.ListNumber = p.listNumbers[ctx.Depth]
+	ctx.Block = block
+
+	renderer, ok := p.renderers[block.GetType()]
+	if !ok {
+		return p.processUnsupported(w, block)
+	}
+	return renderer.Render(&ctx, block, w)
+}
+
+func (p *BlockProcessor) processUnsupported(w io.Writer, block notionapi.Block) error {
+	skipped := converter.SkippedBlock{
+		Type: string(block.GetType()),
+		ID:   string(block.GetID()),
+	}
+	if skipped.Type == "" {
+		// notionapi 无法识别的块类型不会保留原始字段
+		skipped.Type = string(notionapi.BlockTypeUnsupported)
+	}
+	if p.pageURL != "" {
+		skipped.URL = p.pageURL + "#" + strings.ReplaceAll(skipped.ID, "-", "")
+	}
+
+	if p.blocks.Strict {
+		return fmt.Errorf("%w: %s (%s)", ErrUnsupportedBlock, skipped.Type, skipped.ID)
+	}
+	p.skipped = append(p.skipped, skipped)
+
+	if p.blocks.Unsupported == "comment" {
+		_, err := fmt.Fprintf(w, "<!-- notion2md: unsupported block %s %s -->\n\n", skipped.Type, skipped.ID)
 		return err
-	case *notionapi.TableBlock:
-		return p.processTable(w, b)
-	case *notionapi.ColumnListBlock:
-		return p.processColumns(w, b)
 	}
 	return nil
 }
@@ -102,36 +205,89 @@ func (p *BlockProcessor) processHeading(w io.Writer, text []notionapi.RichText,
 	return err
 }
 
-func (p *BlockProcessor) processParagraph(w io.Writer, block *notionapi.ParagraphBlock) error {
+func (p *BlockProcessor) processParagraph(ctx *RenderContext, w io.Writer, block *notionapi.ParagraphBlock) error {
 	text := p.processRichText(block.Paragraph.RichText)
 	if text == "" {
-		_, err := fmt.Fprintln(w)
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprintf(w, "%s\n\n", text); err != nil {
 		return err
 	}
-	_, err := fmt.Fprintf(w, "%s\n\n", text)
-	return err
+
+	// Markdown 没有缩进段落，子块直接跟在段落之后
+	return ctx.RenderChildren(w, block.Paragraph.Children)
 }
 
 func (p *BlockProcessor) processRichText(text []notionapi.RichText) string {
 	var buf bytes.Buffer
 	for _, t := range text {
-		switch t.Type {
-		case notionapi.ObjectTypeText:
-			content := t.Text.Content
-			if t.Annotations.Bold {
-				content = fmt.Sprintf("**%s**", content)
-			}
-			if t.Annotations.Italic {
-				content = fmt.Sprintf("*%s*", content)
-			}
-			if t.Annotations.Strikethrough {
-				content = fmt.Sprintf("~~%s~~", content)
+		switch {
+		case t.Type == notionapi.ObjectTypeText && t.Text != nil:
+			content := annotate(t.Text.Content, t.Annotations)
+			if t.Text.Link != nil {
+				content = fmt.Sprintf("[%s](%s)", content, t.Text.Link.Url)
 			}
-			if t.Annotations.Code {
-				content = fmt.Sprintf("`%s`", content)
+			buf.WriteString(content)
+		case t.Equation != nil:
+			buf.WriteString("$" + t.Equation.Expression + "$")
+		default:
+			// 提及等其他类型使用纯文本，有链接时保留链接
+			content := annotate(t.PlainText, t.Annotations)
+			if t.Href != "" {
+				content = fmt.Sprintf("[%s](%s)", content, t.Href)
 			}
+			buf.WriteString(content)
+		}
+	}
+	return buf.String()
+}
+
+// annotate 为文本加上注解对应的 Markdown 标记
+//
+// 代码标记在最内层，否则其他标记会被当作代码原样输出；首尾空白放在标记之外，
+// 否则 "**粗体 **" 这样的标记不会生效。
+func annotate(content string, a *notionapi.Annotations) string {
+	if a == nil || strings.TrimSpace(content) == "" {
+		return content
+	}
+	trimmed := strings.TrimSpace(content)
+	start := strings.Index(content, trimmed)
+	leading, trailing := content[:start], content[start+len(trimmed):]
+
+	if a.Code {
+		trimmed = "`" + trimmed + "`"
+	}
+	if a.Bold {
+		trimmed = "**" + trimmed + "**"
+	}
+	if a.Italic {
+		trimmed = "*" + trimmed + "*"
+	}
+	if a.Strikethrough {
+		trimmed = "~~" + trimmed + "~~"
+	}
+	return leading + trimmed + trailing
+}
+
+// processRichTextHTML 将富文本转换为转义后的 HTML，用于 HTML 回退输出
+func (p *BlockProcessor) processRichTextHTML(text []notionapi.RichText) string {
+	var buf bytes.Buffer
+	for _, t := range text {
+		switch {
+		case t.Type == notionapi.ObjectTypeText && t.Text != nil:
+			content := annotateHTML(t.Text.Content, t.Annotations)
 			if t.Text.Link != nil {
-				content = fmt.Sprintf("[%s](%s)", content, t.Text.Link.Url)
+				content = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(t.Text.Link.Url), content)
+			}
+			buf.WriteString(content)
+		case t.Equation != nil:
+			buf.WriteString(html.EscapeString("$" + t.Equation.Expression + "$"))
+		default:
+			// 提及等其他类型使用纯文本，有链接时保留链接
+			content := annotateHTML(t.PlainText, t.Annotations)
+			if t.Href != "" {
+				content = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(t.Href), content)
 			}
 			buf.WriteString(content)
 		}
@@ -139,7 +295,31 @@ func (p *BlockProcessor) processRichText(text []notionapi.RichText) string {
 	return buf.String()
 }
 
-func (p *BlockProcessor) processBulletList(w io.Writer, block *notionapi.BulletedListItemBlock) error {
+// annotateHTML 转义文本并加上注解对应的 HTML 标签，代码标签在最内层
+func annotateHTML(content string, a *notionapi.Annotations) string {
+	content = strings.ReplaceAll(html.EscapeString(content), "\n", "<br>")
+	if a == nil || content == "" {
+		return content
+	}
+	if a.Code {
+		content = "<code>" + content + "</code>"
+	}
+	if a.Bold {
+		content = "<strong>" + content + "</strong>"
+	}
+	if a.Italic {
+		content = "<em>" + content + "</em>"
+	}
+	if a.Strikethrough {
+		content = "<del>" + content + "</del>"
+	}
+	if a.Underline {
+		content = "<u>" + content + "</u>"
+	}
+	return content
+}
+
+func (p *BlockProcessor) processBulletList(ctx *RenderContext, w io.Writer, block *notionapi.BulletedListItemBlock) error {
 	text := p.processRichText(block.BulletedListItem.RichText)
 	_, err := fmt.Fprintf(w, "- %s\n", text)
 	if err != nil {
@@ -147,21 +327,13 @@ func (p *BlockProcessor) processBulletList(w io.Writer, block *notionapi.Bullete
 	}
 
 	// 处理子项
-	if len(block.BulletedListItem.Children) > 0 {
-		for _, child := range block.BulletedListItem.Children {
-			_, err := fmt.Fprint(w, "  ") // 缩进
-			if err != nil {
-				return err
-			}
-			if err := p.ProcessBlock(child, w); err != nil {
-				return err
-			}
-		}
+	if err := ctx.renderChildren(w, block.BulletedListItem.Children, "  "); err != nil {
+		return err
 	}
-	return nil
+	return endList(ctx, w)
 }
 
-func (p *BlockProcessor) processNumberedList(w io.Writer, block *notionapi.NumberedListItemBlock) error {
+func (p *BlockProcessor) processNumberedList(ctx *RenderContext, w io.Writer, block *notionapi.NumberedListItemBlock) error {
 	text := p.processRichText(block.NumberedListItem.RichText)
 	_, err := fmt.Fprintf(w, "1. %s\n", text)
 	if err != nil {
@@ -169,71 +341,142 @@ func (p *BlockProcessor) processNumberedList(w io.Writer, block *notionapi.Numbe
 	}
 
 	// 处理子项
-	if len(block.NumberedListItem.Children) > 0 {
-		for _, child := range block.NumberedListItem.Children {
-			_, err := fmt.Fprint(w, "   ") // 缩进
-			if err != nil {
-				return err
-			}
-			if err := p.ProcessBlock(child, w); err != nil {
-				return err
-			}
-		}
+	if err := ctx.renderChildren(w, block.NumberedListItem.Children, "   "); err != nil {
+		return err
 	}
-	return nil
+	return endList(ctx, w)
 }
 
-func (p *BlockProcessor) processTodo(w io.Writer, block *notionapi.ToDoBlock) error {
+func (p *BlockProcessor) processTodo(ctx *RenderContext, w io.Writer, block *notionapi.ToDoBlock) error {
 	text := p.processRichText(block.ToDo.RichText)
 	checkbox := "[ ]"
 	if block.ToDo.Checked {
 		checkbox = "[x]"
 	}
 	_, err := fmt.Fprintf(w, "- %s %s\n", checkbox, text)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.renderChildren(w, block.ToDo.Children, "  "); err != nil {
+		return err
+	}
+	return endList(ctx, w)
+}
+
+// endList 在列表的最后一项之后输出空行，否则后面的段落会被当作列表项的延续
+//
+// 嵌套列表的最后一项不输出空行，由外层列表项负责结束，以免列表变为松散列表。
+func endList(ctx *RenderContext, w io.Writer) error {
+	if ctx.Next != nil && ctx.Next.GetType() == ctx.Block.GetType() {
+		return nil
+	}
+	if ctx.Next == nil && isListItem(ctx.Parent) {
+		return nil
+	}
+	_, err := fmt.Fprintln(w)
 	return err
 }
 
-func (p *BlockProcessor) processToggle(w io.Writer, block *notionapi.ToggleBlock) error {
+func isListItem(block notionapi.Block) bool {
+	switch block.(type) {
+	case *notionapi.BulletedListItemBlock, *notionapi.NumberedListItemBlock, *notionapi.ToDoBlock:
+		return true
+	}
+	return false
+}
+
+func (p *BlockProcessor) processToggle(ctx *RenderContext, w io.Writer, block *notionapi.ToggleBlock) error {
 	summary := p.processRichText(block.Toggle.RichText)
+
+	// CommonMark 不使用 HTML，折叠块展开为加粗标题和内容
+	if p.flavor == FlavorCommonMark {
+		if _, err := fmt.Fprintf(w, "**%s**\n\n", summary); err != nil {
+			return err
+		}
+		return ctx.RenderChildren(w, block.Toggle.Children)
+	}
+
 	_, err := fmt.Fprintf(w, "<details>\n<summary>%s</summary>\n\n", summary)
 	if err != nil {
 		return err
 	}
 
 	// 处理子内容
-	for _, child := range block.Toggle.Children {
-		if err := p.ProcessBlock(child, w); err != nil {
-			return err
-		}
+	if err := ctx.RenderChildren(w, block.Toggle.Children); err != nil {
+		return err
 	}
 
-	_, err = fmt.Fprintln(w, "</details>")
+	// HTML 块需要空行结束，否则后面的 Markdown 不会被解析
+	_, err = fmt.Fprint(w, "</details>\n\n")
 	return err
 }
 
-func (p *BlockProcessor) processQuote(w io.Writer, block *notionapi.QuoteBlock) error {
-	text := p.processRichText(block.Quote.RichText)
-	lines := strings.Split(text, "\n")
+func (p *BlockProcessor) processQuote(ctx *RenderContext, w io.Writer, block *notionapi.QuoteBlock) error {
+	var buf bytes.Buffer
+	buf.WriteString(p.processRichText(block.Quote.RichText) + "\n")
+	if len(block.Quote.Children) > 0 {
+		buf.WriteString("\n")
+		if err := ctx.RenderChildren(&buf, block.Quote.Children); err != nil {
+			return err
+		}
+	}
+
+	// 空行也要加上 >，否则引用会在空行处断开
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
 	for _, line := range lines {
-		_, err := fmt.Fprintf(w, "> %s\n", line)
-		if err != nil {
+		prefix := "> "
+		if line == "" {
+			prefix = ">"
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", prefix, line); err != nil {
 			return err
 		}
 	}
-	return nil
+	_, err := fmt.Fprintln(w)
+	return err
 }
 
-func (p *BlockProcessor) processCode(w io.Writer, block *notionapi.CodeBlock) error {
-	code := p.processRichText(block.Code.RichText)
-	language := block.Code.Language
-	if language == "plain text" {
-		language = ""
+func (p *BlockProcessor) processCode(ctx *RenderContext, w io.Writer, block *notionapi.CodeBlock) error {
+	// 代码内容不应用注解，直接使用纯文本
+	code := processRichText(block.Code.RichText)
+	language := codeLanguage(block.Code.Language, p.code.Languages)
+	caption := processRichText(block.Code.Caption)
+
+	// mermaid 图表
+	if language == "mermaid" && p.useShortcodes && p.code.MermaidShortcode != "" {
+		_, err := fmt.Fprintf(w, "{{< %[1]s >}}\n%[2]s\n{{< /%[1]s >}}\n\n", p.code.MermaidShortcode, code)
+		return err
 	}
-	_, err := fmt.Fprintf(w, "```%s\n%s\n```\n\n", language, code)
-	return err
+
+	// 代码中包含反引号围栏时加长围栏
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	// Hugo 中标题作为代码围栏的 title 属性输出
+	info := language
+	if caption != "" && p.flavor == FlavorHugo {
+		if info == "" {
+			info = "text"
+		}
+		info = fmt.Sprintf("%s {title=%q}", info, caption)
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s\n%s\n%s\n\n", fence, info, code, fence); err != nil {
+		return err
+	}
+
+	// 可移植的 Markdown 没有围栏属性，标题输出在代码块之后
+	if caption != "" && p.flavor != FlavorHugo {
+		_, err := fmt.Fprintf(w, "*%s*\n\n", caption)
+		return err
+	}
+	return nil
 }
 
-func (p *BlockProcessor) processCallout(w io.Writer, block *notionapi.CalloutBlock) error {
+func (p *BlockProcessor) processCallout(ctx *RenderContext, w io.Writer, block *notionapi.CalloutBlock) error {
 	text := p.processRichText(block.Callout.RichText)
 	icon := "💡" // 默认图标
 	if block.Callout.Icon != nil {
@@ -248,24 +491,43 @@ func (p *BlockProcessor) processCallout(w io.Writer, block *notionapi.CalloutBlo
 			icon = "📎"
 		}
 	}
-	_, err := fmt.Fprintf(w, "> %s %s\n\n", icon, text)
+
+	// GFM 使用 GitHub 的提示块语法
+	if p.flavor == FlavorGFM {
+		_, err := fmt.Fprintf(w, "> [!%s]\n> %s\n\n", alertType(icon), strings.ReplaceAll(text, "\n", "\n> "))
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "> %s %s\n\n", icon, strings.ReplaceAll(text, "\n", "\n> "))
 	return err
 }
 
-func (p *BlockProcessor) processImage(w io.Writer, block *notionapi.ImageBlock) error {
+// alertType 根据标注图标选择 GitHub 提示块类型
+func alertType(icon string) string {
+	switch icon {
+	case "💡":
+		return "TIP"
+	case "⚠️", "⚠":
+		return "WARNING"
+	case "❗", "‼️", "📌":
+		return "IMPORTANT"
+	case "🚨", "⛔", "🛑", "❌":
+		return "CAUTION"
+	}
+	return "NOTE"
+}
+
+func (p *BlockProcessor) processImage(ctx *RenderContext, w io.Writer, block *notionapi.ImageBlock) error {
 	caption := p.processRichText(block.Image.Caption)
 	if caption == "" {
 		caption = "image"
 	}
 
-	url := block.Image.File.URL
-	if block.Image.Type == "external" {
-		url = block.Image.External.URL
-	}
+	url := block.Image.GetURL()
 
 	// 如果配置了媒体处理器，使用它处理图片
-	if p.mediaHandler != nil {
-		newURL, err := p.mediaHandler.SaveMedia(url)
+	if ctx.MediaHandler != nil {
+		newURL, err := ctx.MediaHandler.SaveMedia(url)
 		if err != nil {
 			return fmt.Errorf("处理图片失败: %w", err)
 		}
@@ -276,16 +538,33 @@ func (p *BlockProcessor) processImage(w io.Writer, block *notionapi.ImageBlock)
 	return err
 }
 
-func (p *BlockProcessor) processVideo(w io.Writer, block *notionapi.VideoBlock) error {
-	url := block.Video.File.URL
-	if block.Video.Type == "external" {
+func (p *BlockProcessor) processVideo(ctx *RenderContext, w io.Writer, block *notionapi.VideoBlock) error {
+	var url string
+	switch {
+	case block.Video.External != nil:
 		url = block.Video.External.URL
+	case block.Video.File != nil:
+		url = block.Video.File.URL
 	}
 
 	// 处理 YouTube 视频
 	if strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be") {
 		videoID := extractYouTubeID(url)
-		_, err := fmt.Fprintf(w, "{{< youtube %s >}}\n\n", videoID)
+		if p.useShortcodes && p.shortcodes.YouTube != "" {
+			_, err := fmt.Fprintf(w, p.shortcodes.YouTube+"\n\n", videoID)
+			return err
+		}
+		if p.flavor != FlavorHugo {
+			// 可移植的 Markdown 使用缩略图链接
+			_, err := fmt.Fprintf(w, "[![YouTube](https://img.youtube.com/vi/%[1]s/0.jpg)](https://www.youtube.com/watch?v=%[1]s)\n\n", videoID)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "<iframe src=\"https://www.youtube.com/embed/%s\" allowfullscreen></iframe>\n\n", videoID)
+		return err
+	}
+
+	if p.flavor != FlavorHugo {
+		_, err := fmt.Fprintf(w, "[video](%s)\n\n", url)
 		return err
 	}
 
@@ -294,7 +573,7 @@ func (p *BlockProcessor) processVideo(w io.Writer, block *notionapi.VideoBlock)
 	return err
 }
 
-func (p *BlockProcessor) processFile(w io.Writer, block *notionapi.FileBlock) error {
+func (p *BlockProcessor) processFile(ctx *RenderContext, w io.Writer, block *notionapi.FileBlock) error {
 	var url, filename string
 
 	switch block.File.Type {
@@ -312,12 +591,14 @@ func (p *BlockProcessor) processFile(w io.Writer, block *notionapi.FileBlock) er
 
 	// 如果是 PDF，使用特殊处理
 	if strings.HasSuffix(strings.ToLower(filename), ".pdf") {
-		if p.config.UseShortcodes {
-			_, err := fmt.Fprintf(w, "{{< pdf src=\"%s\" >}}\n\n", url)
+		if p.useShortcodes && p.shortcodes.PDF != "" {
+			_, err := fmt.Fprintf(w, p.shortcodes.PDF+"\n\n", url)
+			return err
+		}
+		if p.flavor == FlavorHugo {
+			_, err := fmt.Fprintf(w, "<embed src=\"%s\" type=\"application/pdf\" width=\"100%%\" height=\"600px\">\n\n", url)
 			return err
 		}
-		_, err := fmt.Fprintf(w, "<embed src=\"%s\" type=\"application/pdf\" width=\"100%%\" height=\"600px\">\n\n", url)
-		return err
 	}
 
 	// 普通文件生成下载链接
@@ -325,7 +606,7 @@ func (p *BlockProcessor) processFile(w io.Writer, block *notionapi.FileBlock) er
 	return err
 }
 
-func (p *BlockProcessor) processBookmark(w io.Writer, block *notionapi.BookmarkBlock) error {
+func (p *BlockProcessor) processBookmark(ctx *RenderContext, w io.Writer, block *notionapi.BookmarkBlock) error {
 	title := block.Bookmark.URL
 	if len(block.Bookmark.Caption) > 0 {
 		title = p.processRichText(block.Bookmark.Caption)
@@ -334,51 +615,149 @@ func (p *BlockProcessor) processBookmark(w io.Writer, block *notionapi.BookmarkB
 	return err
 }
 
-func (p *BlockProcessor) processEquation(w io.Writer, block *notionapi.EquationBlock) error {
+func (p *BlockProcessor) processEquation(ctx *RenderContext, w io.Writer, block *notionapi.EquationBlock) error {
 	_, err := fmt.Fprintf(w, "$$\n%s\n$$\n\n", block.Equation.Expression)
 	return err
 }
 
-func (p *BlockProcessor) processTable(w io.Writer, block *notionapi.TableBlock) error {
-	if len(block.Table.Children) == 0 {
+func (p *BlockProcessor) processTable(ctx *RenderContext, w io.Writer, block *notionapi.TableBlock) error {
+	rows := make([]*notionapi.TableRowBlock, 0, len(block.Table.Children))
+	for _, child := range block.Table.Children {
+		if row, ok := child.(*notionapi.TableRowBlock); ok {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) == 0 {
 		return nil
 	}
 
-	// 处理表头
-	firstRow := block.Table.Children[0].(*notionapi.TableRowBlock)
-	for i, cell := range firstRow.TableRow.Cells {
-		if i > 0 {
-			fmt.Fprint(w, " | ")
+	// CommonMark 没有表格语法
+	if p.flavor == FlavorCommonMark {
+		return p.processHTMLTable(w, block, rows)
+	}
+
+	// 单元格中含有换行等 Markdown 表格无法表达的内容时，退回 HTML 表格
+	for _, row := range rows {
+		for _, cell := range row.TableRow.Cells {
+			if cellNeedsHTML(cell) {
+				return p.processHTMLTable(w, block, rows)
+			}
 		}
-		fmt.Fprint(w, p.processRichText(cell))
 	}
-	fmt.Fprintln(w)
 
-	// 分隔线
-	for i := 0; i < len(firstRow.TableRow.Cells); i++ {
-		if i > 0 {
-			fmt.Fprint(w, " | ")
+	width := block.Table.TableWidth
+	for _, row := range rows {
+		if len(row.TableRow.Cells) > width {
+			width = len(row.TableRow.Cells)
 		}
-		fmt.Fprint(w, "---")
 	}
-	fmt.Fprintln(w)
+
+	// Markdown 表格必须有表头，没有列标题时输出空表头
+	body := rows
+	header := make([]string, width)
+	if block.Table.HasColumnHeader {
+		header = p.tableCells(rows[0], width, false)
+		body = rows[1:]
+	}
+	if err := writeTableRow(w, header); err != nil {
+		return err
+	}
+
+	// 分隔线
+	separator := make([]string, width)
+	for i := range separator {
+		separator[i] = "---"
+	}
+	if err := writeTableRow(w, separator); err != nil {
+		return err
+	}
 
 	// 处理数据行
-	for i := 1; i < len(block.Table.Children); i++ {
-		row := block.Table.Children[i].(*notionapi.TableRowBlock)
-		for j, cell := range row.TableRow.Cells {
-			if j > 0 {
-				fmt.Fprint(w, " | ")
+	for _, row := range body {
+		if err := writeTableRow(w, p.tableCells(row, width, block.Table.HasRowHeader)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// tableCells 将一行转换为转义后的 Markdown 单元格，rowHeader 为 true 时首列加粗
+func (p *BlockProcessor) tableCells(row *notionapi.TableRowBlock, width int, rowHeader bool) []string {
+	cells := make([]string, width)
+	for i, cell := range row.TableRow.Cells {
+		if i >= width {
+			break
+		}
+		text := strings.ReplaceAll(p.processRichText(cell), "|", "\\|")
+		if rowHeader && i == 0 && text != "" {
+			text = fmt.Sprintf("**%s**", text)
+		}
+		cells[i] = text
+	}
+	return cells
+}
+
+func writeTableRow(w io.Writer, cells []string) error {
+	_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+// cellNeedsHTML 判断单元格内容是否无法用 Markdown 表格表达
+func cellNeedsHTML(cell []notionapi.RichText) bool {
+	for _, t := range cell {
+		if t.Type == notionapi.ObjectTypeText && t.Text != nil && strings.Contains(t.Text.Content, "\n") {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *BlockProcessor) processHTMLTable(w io.Writer, block *notionapi.TableBlock, rows []*notionapi.TableRowBlock) error {
+	var buf bytes.Buffer
+	buf.WriteString("<table>\n")
+
+	body := rows
+	if block.Table.HasColumnHeader {
+		buf.WriteString("<thead>\n<tr>")
+		for _, cell := range rows[0].TableRow.Cells {
+			fmt.Fprintf(&buf, "<th>%s</th>", p.processRichTextHTML(cell))
+		}
+		buf.WriteString("</tr>\n</thead>\n")
+		body = rows[1:]
+	}
+
+	buf.WriteString("<tbody>\n")
+	for _, row := range body {
+		buf.WriteString("<tr>")
+		for i, cell := range row.TableRow.Cells {
+			if block.Table.HasRowHeader && i == 0 {
+				fmt.Fprintf(&buf, "<th scope=\"row\">%s</th>", p.processRichTextHTML(cell))
+				continue
 			}
-			fmt.Fprint(w, p.processRichText(cell))
+			fmt.Fprintf(&buf, "<td>%s</td>", p.processRichTextHTML(cell))
 		}
-		fmt.Fprintln(w)
+		buf.WriteString("</tr>\n")
 	}
-	fmt.Fprintln(w)
-	return nil
+	buf.WriteString("</tbody>\n</table>\n\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
 }
 
-func (p *BlockProcessor) processColumns(w io.Writer, block *notionapi.ColumnListBlock) error {
+func (p *BlockProcessor) processColumns(ctx *RenderContext, w io.Writer, block *notionapi.ColumnListBlock) error {
+	// 可移植的 Markdown 没有分栏，按顺序输出各栏内容
+	if p.flavor != FlavorHugo {
+		for _, column := range block.ColumnList.Children {
+			if col, ok := column.(*notionapi.ColumnBlock); ok {
+				if err := ctx.RenderChildren(w, col.Column.Children); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
 	_, err := fmt.Fprintln(w, "<div class=\"row\">")
 	if err != nil {
 		return err
@@ -390,15 +769,13 @@ func (p *BlockProcessor) processColumns(w io.Writer, block *notionapi.ColumnList
 			continue
 		}
 
-		_, err = fmt.Fprintln(w, "<div class=\"col\">")
+		_, err = fmt.Fprint(w, "<div class=\"col\">\n\n")
 		if err != nil {
 			return err
 		}
 
-		for _, block := range col.Column.Children {
-			if err := p.ProcessBlock(block, w); err != nil {
-				return err
-			}
+		if err := ctx.RenderChildren(w, col.Column.Children); err != nil {
+			return err
 		}
 
 		_, err = fmt.Fprintln(w, "</div>")
@@ -407,11 +784,114 @@ func (p *BlockProcessor) processColumns(w io.Writer, block *notionapi.ColumnList
 		}
 	}
 
-	_, err = fmt.Fprintln(w, "</div>")
+	_, err = fmt.Fprint(w, "</div>\n\n")
+	return err
+}
+
+func (p *BlockProcessor) processLinkToPage(ctx *RenderContext, w io.Writer, block *notionapi.LinkToPageBlock) error {
+	var title, link string
+	switch block.LinkToPage.Type {
+	case notionapi.BlockType("page_id"):
+		// 无法解析（例如页面未共享给集成）时回退到 Notion 链接
+		if ctx.LinkResolver != nil {
+			if t, l, err := ctx.LinkResolver.ResolvePage(block.LinkToPage.PageID); err == nil {
+				title, link = t, l
+			}
+		}
+		if link == "" {
+			link = NotionURL(string(block.LinkToPage.PageID))
+		}
+	case notionapi.BlockType("database_id"):
+		link = NotionURL(string(block.LinkToPage.DatabaseID))
+	default:
+		return nil
+	}
+
+	if title == "" {
+		title = link
+	}
+	_, err := fmt.Fprintf(w, "[%s](%s)\n\n", title, link)
 	return err
 }
 
-// 辅助函数
+// NotionURL 返回页面或块 ID 对应的 Notion 链接
+func NotionURL(id string) string {
+	return "https://www.notion.so/" + NormalizeID(id)
+}
+
+// NormalizeID 去掉 ID 中的连字符，Notion 接受两种写法
+func NormalizeID(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}
+
+var pageIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// ParsePageID 从页面 ID 或 Notion 页面链接中解析出不含连字符的页面 ID
+//
+// 支持 https://www.notion.so/<workspace>/<标题>-<ID> 形式的链接，以及预览链接中的 ?p=<ID>。
+func ParsePageID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	candidate := s
+	if u, err := url.Parse(s); err == nil && u.Host != "" {
+		candidate = path.Base(u.Path)
+		if p := u.Query().Get("p"); p != "" {
+			candidate = p
+		}
+	}
+
+	id := strings.ToLower(NormalizeID(candidate))
+	if len(id) > 32 {
+		id = id[len(id)-32:]
+	}
+	if !pageIDPattern.MatchString(id) {
+		return "", fmt.Errorf("无法解析页面 ID: %s", s)
+	}
+	return id, nil
+}
+
+// HasBlockType 判断块树中是否包含指定类型的块
+func HasBlockType(blocks []notionapi.Block, blockType notionapi.BlockType) bool {
+	for _, block := range blocks {
+		if block.GetType() == blockType || HasBlockType(blockChildren(block), blockType) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockChildren 返回已加载的子块
+func blockChildren(block notionapi.Block) []notionapi.Block {
+	switch b := block.(type) {
+	case *notionapi.ParagraphBlock:
+		return b.Paragraph.Children
+	case *notionapi.Heading1Block:
+		return b.Heading1.Children
+	case *notionapi.Heading2Block:
+		return b.Heading2.Children
+	case *notionapi.Heading3Block:
+		return b.Heading3.Children
+	case *notionapi.BulletedListItemBlock:
+		return b.BulletedListItem.Children
+	case *notionapi.NumberedListItemBlock:
+		return b.NumberedListItem.Children
+	case *notionapi.ToDoBlock:
+		return b.ToDo.Children
+	case *notionapi.ToggleBlock:
+		return b.Toggle.Children
+	case *notionapi.QuoteBlock:
+		return b.Quote.Children
+	case *notionapi.CalloutBlock:
+		return b.Callout.Children
+	case *notionapi.ColumnListBlock:
+		return b.ColumnList.Children
+	case *notionapi.ColumnBlock:
+		return b.Column.Children
+	case *notionapi.TableBlock:
+		return b.Table.Children
+	}
+	return nil
+}
+
 func extractYouTubeID(url string) string {
 	if strings.Contains(url, "youtu.be/") {
 		parts := strings.Split(url, "youtu.be/")
@@ -428,28 +908,41 @@ func extractYouTubeID(url string) string {
 	return url
 }
 
+// SupportedBlocks 返回已注册渲染器的块类型
 func (p *BlockProcessor) SupportedBlocks() []string {
-	return []string{
-		"paragraph",
-		"heading_1",
-		"heading_2",
-		"heading_3",
-		"bulleted_list_item",
-		"numbered_list_item",
-		"to_do",
-		"toggle",
-		"quote",
-		"code",
-		"callout",
-		"image",
-		"video",
-		"file",
-		"bookmark",
-		"equation",
-		"divider",
-		"table",
-		"column_list",
+	types := make([]string, 0, len(p.renderers))
+	for t := range p.renderers {
+		types = append(types, string(t))
 	}
+	sort.Strings(types)
+	return types
+}
+
+// CodeLanguage 返回 Notion 代码语言对应的语言名，已应用配置中的 code.languages
+func (p *BlockProcessor) CodeLanguage(language string) string {
+	return codeLanguage(language, p.code.Languages)
+}
+
+// SetPage 设置当前处理的页面，用于生成块的 Notion 链接
+func (p *BlockProcessor) SetPage(page notionapi.Page) {
+	p.pageURL = page.URL
+	p.skipped = nil
+	p.listNumbers = nil
+}
+
+// Skipped 返回当前页面中被跳过的未支持块
+func (p *BlockProcessor) Skipped() []converter.SkippedBlock {
+	return p.skipped
+}
+
+// SetShortcodes 设置目标站点生成器的嵌入语法
+func (p *BlockProcessor) SetShortcodes(shortcodes Shortcodes) {
+	p.shortcodes = shortcodes
+}
+
+// SetLinkResolver 设置 link_to_page 块使用的链接解析器
+func (p *BlockProcessor) SetLinkResolver(resolver converter.LinkResolver) {
+	p.linkResolver = resolver
 }
 
 // 添加 getter 方法
@@ -0,0 +1,31 @@
+package notion
+
+import "testing"
+
+func TestParsePageID(t *testing.T) {
+	const id = "1429989fe8ac4effbc8f57f56486db54"
+	for _, input := range []string{
+		id,
+		"1429989f-e8ac-4eff-bc8f-57f56486db54",
+		"https://www.notion.so/1429989fe8ac4effbc8f57f56486db54",
+		"https://www.notion.so/acme/Hello-World-1429989fe8ac4effbc8f57f56486db54?pvs=4",
+		"https://acme.notion.site/Hello-1429989f-e8ac-4eff-bc8f-57f56486db54#heading",
+		"https://www.notion.so/acme/0123456789abcdef0123456789abcdef?v=1&p=1429989fe8ac4effbc8f57f56486db54",
+		" 1429989FE8AC4EFFBC8F57F56486DB54 ",
+	} {
+		got, err := ParsePageID(input)
+		if err != nil {
+			t.Errorf("ParsePageID(%q) 返回错误: %v", input, err)
+			continue
+		}
+		if got != id {
+			t.Errorf("ParsePageID(%q) = %s，期望 %s", input, got, id)
+		}
+	}
+
+	for _, input := range []string{"", "hello", "https://www.notion.so/acme/Hello-World"} {
+		if _, err := ParsePageID(input); err == nil {
+			t.Errorf("ParsePageID(%q) 应返回错误", input)
+		}
+	}
+}
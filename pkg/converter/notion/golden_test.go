@@ -0,0 +1,143 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"notion2md/pkg/config"
+
+	"github.com/jomei/notionapi"
+)
+
+var update = flag.Bool("update", false, "更新 testdata/golden 下的期望输出")
+
+// fakeMediaHandler 不下载文件，只把媒体 URL 改写到 /media 下
+type fakeMediaHandler struct {
+	saved []string
+}
+
+func (h *fakeMediaHandler) SaveMedia(url string) (string, error) {
+	h.saved = append(h.saved, url)
+	return "/media/" + path.Base(strings.Split(url, "?")[0]), nil
+}
+
+func (h *fakeMediaHandler) SupportedTypes() []string {
+	return []string{"image"}
+}
+
+// fakeLinkResolver 只能解析 pages 中的页面
+type fakeLinkResolver struct {
+	pages map[string]string
+}
+
+func (r fakeLinkResolver) ResolvePage(pageID notionapi.PageID) (string, string, error) {
+	title, ok := r.pages[NormalizeID(string(pageID))]
+	if !ok {
+		return "", "", fmt.Errorf("页面不存在: %s", pageID)
+	}
+	return title, "/posts/" + strings.ToLower(strings.ReplaceAll(title, " ", "-")) + "/", nil
+}
+
+var flavors = []string{FlavorHugo, FlavorGFM, FlavorCommonMark}
+
+// TestGolden 使用 testdata/blocks 中的块树渲染每种风格，并与 testdata/golden 比较
+func TestGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "blocks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("testdata/blocks 中没有块树")
+	}
+
+	for _, fixture := range fixtures {
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		blocks := loadBlocks(t, fixture)
+
+		for _, flavor := range flavors {
+			t.Run(flavor+"/"+name, func(t *testing.T) {
+				var config config.Config
+				config.Flavor = flavor
+				config.Blocks.Breadcrumb = "<!-- breadcrumb -->"
+				p := NewBlockProcessor(&fakeMediaHandler{}, &config)
+				p.SetLinkResolver(fakeLinkResolver{pages: map[string]string{
+					"8f1c2b7e000040008000000000000001": "Another page",
+				}})
+
+				var got bytes.Buffer
+				if err := p.ProcessBlocks(blocks, &got); err != nil {
+					t.Fatalf("渲染失败: %v", err)
+				}
+
+				compareGolden(t, filepath.Join("testdata", "golden", flavor, name+".md"), got.Bytes())
+			})
+		}
+	}
+}
+
+// TestGoldenCoversSupportedBlocks 确保每种已注册的块类型都有 golden 测试
+func TestGoldenCoversSupportedBlocks(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "blocks", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := make(map[string]bool)
+	var walk func(blocks []notionapi.Block)
+	walk = func(blocks []notionapi.Block) {
+		for _, block := range blocks {
+			covered[string(block.GetType())] = true
+			walk(blockChildren(block))
+		}
+	}
+	for _, fixture := range fixtures {
+		walk(loadBlocks(t, fixture))
+	}
+
+	for _, blockType := range NewBlockProcessor(nil, &config.Config{}).SupportedBlocks() {
+		if !covered[blockType] {
+			t.Errorf("testdata/blocks 中没有 %s 块", blockType)
+		}
+	}
+}
+
+func loadBlocks(t *testing.T, path string) notionapi.Blocks {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks notionapi.Blocks
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		t.Fatalf("解析 %s 失败: %v", path, err)
+	}
+	return blocks
+}
+
+func compareGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("读取 golden 文件失败（使用 -update 生成）: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s 输出不一致\n--- got ---\n%s\n--- want ---\n%s", golden, got, want)
+	}
+}
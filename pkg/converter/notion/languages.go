@@ -0,0 +1,54 @@
+package notion
+
+import "strings"
+
+// codeLanguages 将 Notion 代码块的语言名映射为 Chroma 词法分析器名称
+// 名称与 Chroma 一致的语言不在表中，按原样（小写）输出
+var codeLanguages = map[string]string{
+	"plain text":    "",
+	"c++":           "cpp",
+	"c#":            "csharp",
+	"f#":            "fsharp",
+	"objective-c":   "objectivec",
+	"shell":         "bash",
+	"vb.net":        "vbnet",
+	"visual basic":  "vbnet",
+	"basic":         "qbasic",
+	"flow":          "javascript",
+	"lisp":          "common-lisp",
+	"livescript":    "",
+	"markup":        "html",
+	"pascal":        "objectpascal",
+	"reason":        "reasonml",
+	"webassembly":   "wast",
+	"java/c/c++/c#": "java",
+}
+
+// languageKey 返回语言名的查找键，忽略大小写和首尾空白
+func languageKey(language string) string {
+	return strings.ToLower(strings.TrimSpace(language))
+}
+
+// normalizeLanguages 将用户配置的语言映射的键转换为查找键，例如 "Plain Text" 转换为 "plain text"
+func normalizeLanguages(overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return overrides
+	}
+	normalized := make(map[string]string, len(overrides))
+	for language, lang := range overrides {
+		normalized[languageKey(language)] = lang
+	}
+	return normalized
+}
+
+// codeLanguage 返回 Notion 语言名对应的代码围栏语言，overrides 优先于内置映射，键须为 languageKey 的结果
+func codeLanguage(language string, overrides map[string]string) string {
+	key := languageKey(language)
+	if lang, ok := overrides[key]; ok {
+		return lang
+	}
+	if lang, ok := codeLanguages[key]; ok {
+		return lang
+	}
+	return strings.ReplaceAll(key, " ", "")
+}
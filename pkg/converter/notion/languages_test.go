@@ -0,0 +1,32 @@
+package notion
+
+import (
+	"testing"
+
+	"notion2md/pkg/config"
+)
+
+func TestCodeLanguage(t *testing.T) {
+	var c config.Config
+	// 用户配置的键与 Notion 的语言名大小写不同
+	c.Code.Languages = map[string]string{"Plain Text": "text", " C# ": "cs", "Go": "golang"}
+	p := NewBlockProcessor(&fakeMediaHandler{}, &c)
+
+	for _, tt := range []struct {
+		language string
+		want     string
+	}{
+		{"plain text", "text"},
+		{"Plain Text", "text"},
+		{"c#", "cs"},
+		{"C#", "cs"},
+		{"go", "golang"},
+		{"C++", "cpp"},
+		{"Visual Basic", "vbnet"},
+		{"Python", "python"},
+	} {
+		if got := codeLanguage(tt.language, p.code.Languages); got != tt.want {
+			t.Errorf("%q 的语言为 %q，期望 %q", tt.language, got, tt.want)
+		}
+	}
+}
@@ -1,60 +1,42 @@
 package notion
 
 import (
-	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
-	"notion2md/pkg/converter"
+	"notion2md/pkg/config"
 
 	"github.com/jomei/notionapi"
 )
 
 type MetadataProcessor struct {
-	config struct {
-		CategoryMap map[string]string
-		Status      struct {
-			Draft string
-		}
-		Properties struct {
-			Title       string `json:"title"`
-			Categories  string `json:"categories"`
-			Tags        string `json:"tags"`
-			Status      string `json:"status"`
-			Description string `json:"description"`
-			Author      string `json:"author"`
-			MetaTitle   string `json:"metaTitle"`
-			Slug        string `json:"slug"`
-			Toc         string `json:"toc"`
-			Comments    string `json:"comments"`
-			Weight      string `json:"weight"`
-		}
-	}
+	config config.NotionConfig
 }
 
-func NewMetadataProcessor(config *converter.Config) *MetadataProcessor {
-	p := &MetadataProcessor{}
-	p.config.CategoryMap = config.Notion.CategoryMap
-	p.config.Status.Draft = config.Notion.Status.Draft
-	p.config.Properties = config.Notion.Properties
-	return p
+func NewMetadataProcessor(config *config.Config) *MetadataProcessor {
+	return &MetadataProcessor{config: config.Notion}
 }
 
 func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]interface{}, error) {
 	metadata := make(map[string]interface{})
 
-	// 调试输出所有属性
-	for name, prop := range page.Properties {
-		fmt.Printf("Property %s: %T\n", name, prop)
-	}
-
 	// 基本字段
-	if title, ok := page.Properties["Name"].(*notionapi.TitleProperty); ok {
+	if title, ok := page.Properties[propertyName(p.config.Properties.Title, "Name")].(*notionapi.TitleProperty); ok {
 		metadata["title"] = processRichText(title.Title)
 	}
 	metadata["date"] = page.CreatedTime.Format(time.RFC3339)
 	metadata["lastmod"] = page.LastEditedTime.Format(time.RFC3339)
 
+	// 设置了发布日期时以其作为文章日期
+	if publishDate := p.PublishDate(page); !publishDate.IsZero() {
+		metadata["date"] = publishDate.Format(time.RFC3339)
+		metadata["publish_date"] = publishDate.Format(time.RFC3339)
+	}
+	if expiryDate := dateProperty(page, p.config.Properties.ExpiryDate); !expiryDate.IsZero() {
+		metadata["expiry_date"] = expiryDate.Format(time.RFC3339)
+	}
+
 	// 处理封面图片
 	if page.Cover != nil {
 		metadata["cover"] = page.Cover.GetURL()
@@ -64,7 +46,12 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 	var originalCategories []string
 	var mappedCategories []string
 
-	if cats, ok := page.Properties["Category"].(*notionapi.SelectProperty); ok {
+	// 未配置分类属性时依次使用单选的 Category 和多选的 Categories
+	categoryProperty, categoriesProperty := "Category", "Categories"
+	if p.config.Properties.Categories != "" {
+		categoryProperty, categoriesProperty = p.config.Properties.Categories, p.config.Properties.Categories
+	}
+	if cats, ok := page.Properties[categoryProperty].(*notionapi.SelectProperty); ok {
 		// 单选
 		if cats.Select.Name != "" {
 			if mapped, exists := p.config.CategoryMap[cats.Select.Name]; exists {
@@ -72,11 +59,11 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 				mappedCategories = []string{mapped}
 			} else {
 				// 如果没有映射，跳过这篇文章
-				fmt.Printf("警告: 分类 '%s' 未配置映射，跳过文章\n", cats.Select.Name)
+				slog.Warn("分类未配置映射，跳过文章", "page_id", string(page.ID), "category", cats.Select.Name)
 				return nil, nil
 			}
 		}
-	} else if cats, ok := page.Properties["Categories"].(*notionapi.MultiSelectProperty); ok {
+	} else if cats, ok := page.Properties[categoriesProperty].(*notionapi.MultiSelectProperty); ok {
 		// 多选
 		originalCategories = make([]string, 0, len(cats.MultiSelect))
 		mappedCategories = make([]string, 0, len(cats.MultiSelect))
@@ -87,7 +74,7 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 				mappedCategories = append(mappedCategories, mapped)
 			} else {
 				// 如果没有映射，跳过这篇文章
-				fmt.Printf("警告: 分类 '%s' 未配置映射，跳过文章\n", cat.Name)
+				slog.Warn("分类未配置映射，跳过文章", "page_id", string(page.ID), "category", cat.Name)
 				return nil, nil
 			}
 		}
@@ -99,7 +86,7 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 	}
 
 	// 处理标签
-	if tags, ok := page.Properties["Tags"].(*notionapi.MultiSelectProperty); ok {
+	if tags, ok := page.Properties[propertyName(p.config.Properties.Tags, "Tags")].(*notionapi.MultiSelectProperty); ok {
 		tagList := make([]string, 0, len(tags.MultiSelect))
 		for _, tag := range tags.MultiSelect {
 			tagList = append(tagList, tag.Name)
@@ -112,12 +99,12 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 	metadata["author"] = page.CreatedBy.Name
 
 	// 处理状态（草稿）
-	if status, ok := page.Properties["Status"].(*notionapi.StatusProperty); ok {
-		metadata["draft"] = status.Status.Name == p.config.Status.Draft
+	if status := PageStatus(page, propertyName(p.config.Properties.Status, "Status")); status != "" {
+		metadata["draft"] = status == p.config.Status.Draft
 	}
 
 	// 处理描述
-	if desc, ok := page.Properties["Description"].(*notionapi.RichTextProperty); ok {
+	if desc, ok := page.Properties[propertyName(p.config.Properties.Description, "Description")].(*notionapi.RichTextProperty); ok {
 		description := processRichText(desc.RichText)
 		if description != "" {
 			metadata["description"] = description
@@ -125,7 +112,7 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 	}
 
 	// 处理元标题
-	if metaTitle, ok := page.Properties["Meta Title"].(*notionapi.RichTextProperty); ok {
+	if metaTitle, ok := page.Properties[propertyName(p.config.Properties.MetaTitle, "Meta Title")].(*notionapi.RichTextProperty); ok {
 		if len(metaTitle.RichText) > 0 {
 			metadata["meta_title"] = processRichText(metaTitle.RichText)
 		}
@@ -139,23 +126,53 @@ func (p *MetadataProcessor) ProcessMetadata(page notionapi.Page) (map[string]int
 
 func (p *MetadataProcessor) processOptionalFields(page notionapi.Page, metadata map[string]interface{}) {
 	// TOC
-	if toc, ok := page.Properties["Toc"].(*notionapi.CheckboxProperty); ok {
+	if toc, ok := page.Properties[propertyName(p.config.Properties.Toc, "Toc")].(*notionapi.CheckboxProperty); ok {
 		metadata["toc"] = toc.Checkbox
 	}
 
 	// Comments
-	if comments, ok := page.Properties["Comments"].(*notionapi.CheckboxProperty); ok {
+	if comments, ok := page.Properties[propertyName(p.config.Properties.Comments, "Comments")].(*notionapi.CheckboxProperty); ok {
 		metadata["comments"] = comments.Checkbox
 	}
 
 	// Slug
-	if slug, ok := page.Properties["Slug"].(*notionapi.RichTextProperty); ok {
+	if slug, ok := page.Properties[propertyName(p.config.Properties.Slug, "Slug")].(*notionapi.RichTextProperty); ok {
 		if len(slug.RichText) > 0 {
 			metadata["slug"] = processRichText(slug.RichText)
 		}
 	}
 }
 
+// PublishDate 返回页面的计划发布日期，未配置或未填写发布日期属性时返回零值
+func (p *MetadataProcessor) PublishDate(page notionapi.Page) time.Time {
+	return dateProperty(page, p.config.Properties.PublishDate)
+}
+
+// Scheduled 判断页面的发布日期是否晚于 now
+func (p *MetadataProcessor) Scheduled(page notionapi.Page, now time.Time) bool {
+	return p.PublishDate(page).After(now)
+}
+
+// propertyName 返回配置的属性名，未配置时返回默认的属性名
+func propertyName(configured, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
+
+// dateProperty 返回日期属性的开始时间
+func dateProperty(page notionapi.Page, name string) time.Time {
+	if name == "" {
+		return time.Time{}
+	}
+	prop, ok := page.Properties[name].(*notionapi.DateProperty)
+	if !ok || prop.Date == nil || prop.Date.Start == nil {
+		return time.Time{}
+	}
+	return time.Time(*prop.Date.Start)
+}
+
 // 辅助函数
 func processRichText(text []notionapi.RichText) string {
 	var parts []string
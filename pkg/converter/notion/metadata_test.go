@@ -0,0 +1,61 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"notion2md/pkg/config"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestScheduledPublishing(t *testing.T) {
+	var cfg config.Config
+	cfg.Notion.Properties.PublishDate = "Publish Date"
+	cfg.Notion.Properties.ExpiryDate = "Expiry Date"
+	p := NewMetadataProcessor(&cfg)
+
+	var page notionapi.Page
+	err := json.Unmarshal([]byte(`{
+		"id": "page-1",
+		"created_time": "2024-01-01T00:00:00Z",
+		"properties": {
+			"Publish Date": {"type": "date", "date": {"start": "2024-06-01"}},
+			"Expiry Date": {"type": "date", "date": {"start": "2024-12-31T12:00:00Z"}}
+		}
+	}`), &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := p.ProcessMetadata(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["date"] != "2024-06-01T00:00:00Z" || metadata["publish_date"] != "2024-06-01T00:00:00Z" {
+		t.Errorf("date 应使用发布日期: %v / %v", metadata["date"], metadata["publish_date"])
+	}
+	if metadata["expiry_date"] != "2024-12-31T12:00:00Z" {
+		t.Errorf("expiry_date 不正确: %v", metadata["expiry_date"])
+	}
+
+	if !p.Scheduled(page, time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Error("发布日期之前应为计划状态")
+	}
+	if p.Scheduled(page, time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("发布日期之后不应为计划状态")
+	}
+
+	// 未配置发布日期属性时使用创建时间
+	metadata, err = NewMetadataProcessor(&config.Config{}).ProcessMetadata(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["date"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("date 应使用创建时间: %v", metadata["date"])
+	}
+	if _, ok := metadata["publish_date"]; ok {
+		t.Error("未配置时不应设置 publish_date")
+	}
+}
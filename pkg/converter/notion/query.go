@@ -0,0 +1,206 @@
+package notion
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"notion2md/pkg/config"
+
+	"github.com/jomei/notionapi"
+)
+
+// dateConditions 是值为日期条件的键，其中的 "today" 和 "now" 会替换为当前日期和时间
+var dateConditions = map[string]bool{
+	"date":             true,
+	"created_time":     true,
+	"last_edited_time": true,
+}
+
+// NewQuery 根据配置创建数据库查询请求
+//
+// 未配置 notion.query.filter 时查询状态为 ready 或 toDelete 的文章。
+func NewQuery(config *config.Config, now time.Time) (*notionapi.DatabaseQueryRequest, error) {
+	return newQuery(config, now, config.Notion.Status.Ready, config.Notion.Status.ToDelete)
+}
+
+// NewExportQuery 创建导出使用的数据库查询请求
+//
+// 未配置 notion.query.filter 时查询状态为 ready 或 published 的文章。
+func NewExportQuery(config *config.Config, now time.Time) (*notionapi.DatabaseQueryRequest, error) {
+	return newQuery(config, now, config.Notion.Status.Ready, config.Notion.Status.Published)
+}
+
+// newQuery 创建查询请求，未配置过滤条件时查询处于 statuses 中任一状态的文章
+func newQuery(config *config.Config, now time.Time, statuses ...string) (*notionapi.DatabaseQueryRequest, error) {
+	query := &notionapi.DatabaseQueryRequest{
+		Sorts:    config.Notion.Query.Sorts,
+		PageSize: 100,
+	}
+
+	if len(config.Notion.Query.Filter) == 0 {
+		property := StatusProperty(config)
+		var filter notionapi.OrCompoundFilter
+		for _, status := range statuses {
+			// 未配置的状态不查询，例如未配置待删除状态时不查询待删除的文章
+			if status == "" {
+				continue
+			}
+			filter = append(filter, notionapi.PropertyFilter{
+				Property: property,
+				Status:   &notionapi.StatusFilterCondition{Equals: status},
+			})
+		}
+		query.Filter = filter
+		return query, nil
+	}
+
+	filter, err := ParseFilter(config.Notion.Query.Filter, now)
+	if err != nil {
+		return nil, err
+	}
+	query.Filter = filter
+	return query, nil
+}
+
+// EditedSince 在查询条件上追加 last_edited_time 过滤，只查询 since 之后编辑过的页面
+//
+// Notion 的编辑时间精确到分钟，since 会向下取整到分钟，同一分钟内的编辑不会遗漏。
+func EditedSince(query *notionapi.DatabaseQueryRequest, since time.Time) {
+	date := notionapi.Date(since.Truncate(time.Minute))
+	edited := notionapi.TimestampFilter{
+		Timestamp:      notionapi.TimestampLastEdited,
+		LastEditedTime: &notionapi.DateFilterCondition{OnOrAfter: &date},
+	}
+	if query.Filter == nil {
+		query.Filter = edited
+		return
+	}
+	query.Filter = notionapi.AndCompoundFilter{query.Filter, edited}
+}
+
+// TitleProperty 返回标题属性的名称，默认为 Name
+func TitleProperty(config *config.Config) string {
+	if config.Notion.Properties.Title != "" {
+		return config.Notion.Properties.Title
+	}
+	return "Name"
+}
+
+// StatusProperty 返回状态属性的名称，默认为 Status
+func StatusProperty(config *config.Config) string {
+	if config.Notion.Properties.Status != "" {
+		return config.Notion.Properties.Status
+	}
+	return "Status"
+}
+
+// PageStatus 返回页面中名为 property 的状态属性的值，状态属性可以是 Status 或 Select 类型
+func PageStatus(page notionapi.Page, property string) string {
+	switch status := page.Properties[property].(type) {
+	case *notionapi.StatusProperty:
+		return status.Status.Name
+	case *notionapi.SelectProperty:
+		return status.Select.Name
+	}
+	return ""
+}
+
+// ParseFilter 将 Notion API 格式的过滤条件解析为 notionapi 的过滤器
+//
+// 支持 and/or 组合、属性过滤和时间戳过滤，日期条件中的 "today" 和 "now" 按 now 计算。
+func ParseFilter(data []byte, now time.Time) (notionapi.Filter, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析过滤条件失败: %w", err)
+	}
+
+	if and, ok := raw["and"]; ok {
+		filters, err := parseFilters(and, now)
+		if err != nil {
+			return nil, err
+		}
+		return notionapi.AndCompoundFilter(filters), nil
+	}
+	if or, ok := raw["or"]; ok {
+		filters, err := parseFilters(or, now)
+		if err != nil {
+			return nil, err
+		}
+		return notionapi.OrCompoundFilter(filters), nil
+	}
+
+	data, err := resolveDates(data, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := raw["timestamp"]; ok {
+		var filter notionapi.TimestampFilter
+		if err := decodeStrict(data, &filter); err != nil {
+			return nil, fmt.Errorf("解析时间戳过滤条件失败: %w", err)
+		}
+		return filter, nil
+	}
+	if _, ok := raw["property"]; ok {
+		var filter notionapi.PropertyFilter
+		if err := decodeStrict(data, &filter); err != nil {
+			return nil, fmt.Errorf("解析属性过滤条件失败: %w", err)
+		}
+		return filter, nil
+	}
+	return nil, fmt.Errorf("过滤条件必须包含 and、or、property 或 timestamp: %s", data)
+}
+
+func parseFilters(data json.RawMessage, now time.Time) ([]notionapi.Filter, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("组合过滤条件必须是数组: %w", err)
+	}
+	filters := make([]notionapi.Filter, 0, len(items))
+	for _, item := range items {
+		filter, err := ParseFilter(item, now)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// decodeStrict 解码 JSON，拼写错误的字段会报错而不是被忽略
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// resolveDates 替换日期条件中的 "today" 和 "now"
+func resolveDates(data []byte, now time.Time) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(replaceDates(v, false, now))
+}
+
+func replaceDates(v interface{}, inDate bool, now time.Time) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = replaceDates(child, inDate || dateConditions[key], now)
+		}
+	case string:
+		if !inDate {
+			return value
+		}
+		switch value {
+		case "today":
+			return now.Format("2006-01-02")
+		case "now":
+			return now.Format(time.RFC3339)
+		}
+	}
+	return v
+}
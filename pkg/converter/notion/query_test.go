@@ -0,0 +1,124 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"notion2md/pkg/config"
+)
+
+func TestNewQuery(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "default status filter",
+			config: `{"notion": {"status": {"ready": "Ready", "toDelete": "Delete"}}}`,
+			want:   `{"page_size":100,"filter":{"or":[{"property":"Status","status":{"equals":"Ready"}},{"property":"Status","status":{"equals":"Delete"}}]}}`,
+		},
+		{
+			name:   "custom status property",
+			config: `{"notion": {"status": {"ready": "Ready"}, "properties": {"status": "State"}}}`,
+			want:   `{"page_size":100,"filter":{"or":[{"property":"State","status":{"equals":"Ready"}}]}}`,
+		},
+		{
+			name: "compound filter with sorts",
+			config: `{"notion": {"query": {
+				"filter": {"and": [
+					{"property": "Publish", "checkbox": {"equals": true}},
+					{"property": "Publish Date", "date": {"on_or_before": "today"}},
+					{"or": [
+						{"property": "Series", "relation": {"contains": "abc"}},
+						{"timestamp": "last_edited_time", "last_edited_time": {"after": "now"}}
+					]}
+				]},
+				"sorts": [{"property": "Publish Date", "direction": "descending"}]
+			}}}`,
+			want: `{"sorts":[{"property":"Publish Date","direction":"descending"}],"page_size":100,"filter":{"and":[` +
+				`{"property":"Publish","checkbox":{"equals":true}},` +
+				`{"property":"Publish Date","date":{"on_or_before":"2024-05-01T00:00:00Z"}},` +
+				`{"or":[{"property":"Series","relation":{"contains":"abc"}},` +
+				`{"timestamp":"last_edited_time","last_edited_time":{"after":"2024-05-01T08:30:00Z"}}]}]}}`,
+		},
+		{
+			name:   "today outside date conditions",
+			config: `{"notion": {"query": {"filter": {"property": "Slug", "rich_text": {"equals": "today"}}}}}`,
+			want:   `{"page_size":100,"filter":{"property":"Slug","rich_text":{"equals":"today"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config config.Config
+			if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatal(err)
+			}
+			query, err := NewQuery(&config, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.Marshal(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("查询不一致\ngot:  %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewExportQuery(t *testing.T) {
+	var config config.Config
+	config.Notion.Status.Ready = "Ready"
+	config.Notion.Status.Published = "Published"
+	config.Notion.Status.ToDelete = "Delete"
+	query, err := NewExportQuery(&config, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(query)
+	want := `{"page_size":100,"filter":{"or":[{"property":"Status","status":{"equals":"Ready"}},{"property":"Status","status":{"equals":"Published"}}]}}`
+	if string(got) != want {
+		t.Errorf("查询不一致\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, filter := range []string{
+		`{"checkbox": {"equals": true}}`,
+		`{"property": "Publish", "chekbox": {"equals": true}}`,
+		`{"and": {"property": "Publish"}}`,
+		`[]`,
+	} {
+		if _, err := ParseFilter([]byte(filter), time.Now()); err == nil {
+			t.Errorf("过滤条件 %s 应返回错误", filter)
+		}
+	}
+}
+
+func TestEditedSince(t *testing.T) {
+	var config config.Config
+	config.Notion.Status.Ready = "Ready"
+	query, err := NewQuery(&config, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	EditedSince(query, time.Date(2024, 5, 1, 8, 30, 45, 0, time.UTC))
+
+	got, err := json.Marshal(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"page_size":100,"filter":{"and":[` +
+		`{"or":[{"property":"Status","status":{"equals":"Ready"}}]},` +
+		`{"timestamp":"last_edited_time","last_edited_time":{"on_or_after":"2024-05-01T08:30:00Z"}}]}}`
+	if string(got) != want {
+		t.Errorf("查询不一致\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,263 @@
+package notion
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"notion2md/pkg/converter"
+
+	"github.com/jomei/notionapi"
+)
+
+// BlockRenderer 定义了单个块类型的渲染器
+type BlockRenderer interface {
+	// Render 将块渲染到 w
+	Render(ctx *RenderContext, block notionapi.Block, w io.Writer) error
+}
+
+// BlockRendererFunc 允许将普通函数用作 BlockRenderer
+type BlockRendererFunc func(ctx *RenderContext, block notionapi.Block, w io.Writer) error
+
+func (f BlockRendererFunc) Render(ctx *RenderContext, block notionapi.Block, w io.Writer) error {
+	return f(ctx, block, w)
+}
+
+// RenderContext 是渲染单个块时可用的上下文
+type RenderContext struct {
+	// Depth 为嵌套深度，顶层块为 0
+	Depth int
+	// ListNumber 为块在当前有序列表中的序号（从 1 开始），非有序列表项为 0
+	ListNumber int
+	// Block 为当前渲染的块，Parent 为其父块（顶层块为 nil）
+	Block  notionapi.Block
+	Parent notionapi.Block
+	// Prev 和 Next 为同级的前后相邻块，不存在或单独渲染时为 nil
+	Prev notionapi.Block
+	Next notionapi.Block
+
+	MediaHandler converter.MediaHandler
+	LinkResolver converter.LinkResolver
+
+	processor *BlockProcessor
+}
+
+// RichText 将富文本转换为 Markdown
+func (c *RenderContext) RichText(text []notionapi.RichText) string {
+	return c.processor.processRichText(text)
+}
+
+// RenderChildren 以更深一层的嵌套渲染子块
+func (c *RenderContext) RenderChildren(w io.Writer, children []notionapi.Block) error {
+	return c.renderChildren(w, children, "")
+}
+
+// renderChildren 渲染子块，并为输出的每一行加上缩进
+func (c *RenderContext) renderChildren(w io.Writer, children []notionapi.Block, indent string) error {
+	if len(children) == 0 {
+		return nil
+	}
+
+	child := RenderContext{
+		Depth:        c.Depth + 1,
+		Parent:       c.Block,
+		MediaHandler: c.MediaHandler,
+		LinkResolver: c.LinkResolver,
+		processor:    c.processor,
+	}
+
+	// 子块开始新的有序列表
+	if len(c.processor.listNumbers) > child.Depth {
+		c.processor.listNumbers = c.processor.listNumbers[:child.Depth]
+	}
+
+	var buf bytes.Buffer
+	if err := c.processor.renderBlocks(child, children, &buf); err != nil {
+		return err
+	}
+
+	if indent == "" {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			line = indent + line
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RendererFor 将具体块类型的渲染函数包装为 BlockRenderer
+func RendererFor[T notionapi.Block](fn func(ctx *RenderContext, w io.Writer, block T) error) BlockRenderer {
+	return BlockRendererFunc(func(ctx *RenderContext, block notionapi.Block, w io.Writer) error {
+		b, ok := block.(T)
+		if !ok {
+			return fmt.Errorf("块 %s 的类型不匹配: %T", block.GetType(), block)
+		}
+		return fn(ctx, w, b)
+	})
+}
+
+// Register 为块类型注册渲染器，已有的渲染器会被覆盖
+func (p *BlockProcessor) Register(blockType notionapi.BlockType, renderer BlockRenderer) {
+	p.renderers[blockType] = renderer
+}
+
+// RegisterTemplate 使用 Go text/template 文件作为块类型的渲染器
+func (p *BlockProcessor) RegisterTemplate(blockType notionapi.BlockType, path string) error {
+	renderer, err := NewTemplateRenderer(path)
+	if err != nil {
+		return err
+	}
+	p.Register(blockType, renderer)
+	return nil
+}
+
+// TemplateRenderer 使用 Go text/template 渲染块
+//
+// 模板中可用的字段：
+//
+//	.Block       原始的 notionapi 块
+//	.Type / .ID  块类型和 ID
+//	.Text        块主体富文本对应的 Markdown
+//	.Children    已渲染的子块
+//	.Rows        表格块各行单元格的 Markdown（仅 table）
+//	.Columns     分栏块各栏已渲染的内容（仅 column_list）
+//	.Depth       嵌套深度
+//	.ListNumber  有序列表序号
+//
+// 以及函数 richText、plainText 和 media（通过媒体处理器保存文件并返回 URL）。
+type TemplateRenderer struct {
+	tmpl *template.Template
+}
+
+func NewTemplateRenderer(path string) (*TemplateRenderer, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs(nil)).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析块模板失败: %w", err)
+	}
+	return &TemplateRenderer{tmpl: tmpl}, nil
+}
+
+func (r *TemplateRenderer) Render(ctx *RenderContext, block notionapi.Block, w io.Writer) error {
+	data := map[string]interface{}{
+		"Block":      block,
+		"Type":       string(block.GetType()),
+		"ID":         string(block.GetID()),
+		"Text":       ctx.RichText(blockRichText(block)),
+		"Depth":      ctx.Depth,
+		"ListNumber": ctx.ListNumber,
+	}
+
+	// 表格行和分栏没有渲染器，直接提供给模板，而不是作为子块渲染
+	switch b := block.(type) {
+	case *notionapi.TableBlock:
+		var rows [][]string
+		for _, child := range b.Table.Children {
+			row, ok := child.(*notionapi.TableRowBlock)
+			if !ok {
+				continue
+			}
+			cells := make([]string, len(row.TableRow.Cells))
+			for i, cell := range row.TableRow.Cells {
+				cells[i] = ctx.RichText(cell)
+			}
+			rows = append(rows, cells)
+		}
+		data["Rows"] = rows
+	case *notionapi.ColumnListBlock:
+		var columns []string
+		for _, child := range b.ColumnList.Children {
+			column, ok := child.(*notionapi.ColumnBlock)
+			if !ok {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := ctx.RenderChildren(&buf, column.Column.Children); err != nil {
+				return err
+			}
+			columns = append(columns, buf.String())
+		}
+		data["Columns"] = columns
+	default:
+		var children bytes.Buffer
+		if err := ctx.RenderChildren(&children, blockChildren(block)); err != nil {
+			return err
+		}
+		data["Children"] = children.String()
+	}
+
+	tmpl, err := r.tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Funcs(templateFuncs(ctx)).Execute(w, data); err != nil {
+		return fmt.Errorf("渲染块模板失败: %w", err)
+	}
+	return nil
+}
+
+func templateFuncs(ctx *RenderContext) template.FuncMap {
+	return template.FuncMap{
+		"richText": func(text []notionapi.RichText) string {
+			return ctx.RichText(text)
+		},
+		"plainText": processRichText,
+		"media": func(url string) (string, error) {
+			if ctx.MediaHandler == nil {
+				return url, nil
+			}
+			return ctx.MediaHandler.SaveMedia(url)
+		},
+	}
+}
+
+// blockRichText 返回块的主体富文本
+func blockRichText(block notionapi.Block) []notionapi.RichText {
+	switch b := block.(type) {
+	case *notionapi.ParagraphBlock:
+		return b.Paragraph.RichText
+	case *notionapi.Heading1Block:
+		return b.Heading1.RichText
+	case *notionapi.Heading2Block:
+		return b.Heading2.RichText
+	case *notionapi.Heading3Block:
+		return b.Heading3.RichText
+	case *notionapi.BulletedListItemBlock:
+		return b.BulletedListItem.RichText
+	case *notionapi.NumberedListItemBlock:
+		return b.NumberedListItem.RichText
+	case *notionapi.ToDoBlock:
+		return b.ToDo.RichText
+	case *notionapi.ToggleBlock:
+		return b.Toggle.RichText
+	case *notionapi.QuoteBlock:
+		return b.Quote.RichText
+	case *notionapi.CalloutBlock:
+		return b.Callout.RichText
+	case *notionapi.CodeBlock:
+		return b.Code.RichText
+	case *notionapi.ImageBlock:
+		return b.Image.Caption
+	case *notionapi.VideoBlock:
+		return b.Video.Caption
+	case *notionapi.FileBlock:
+		return b.File.Caption
+	case *notionapi.PdfBlock:
+		return b.Pdf.Caption
+	case *notionapi.BookmarkBlock:
+		return b.Bookmark.Caption
+	case *notionapi.EmbedBlock:
+		return b.Embed.Caption
+	}
+	return nil
+}
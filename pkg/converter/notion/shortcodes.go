@@ -0,0 +1,19 @@
+package notion
+
+// Shortcodes 定义了内置渲染器使用的嵌入语法，不同的静态站点生成器语法不同
+// 各字段为 fmt 格式字符串，为空时使用通用的 HTML 或 Markdown 输出
+type Shortcodes struct {
+	// YouTube 的参数为视频 ID
+	YouTube string
+	// PDF 的参数为文件 URL
+	PDF string
+	// TableOfContents 为目录标记，配置中的 blocks.tableOfContents 优先
+	TableOfContents string
+}
+
+// HugoShortcodes 是 Hugo 的嵌入语法
+var HugoShortcodes = Shortcodes{
+	YouTube:         "{{< youtube %s >}}",
+	PDF:             "{{< pdf src=\"%s\" >}}",
+	TableOfContents: "{{< toc >}}",
+}
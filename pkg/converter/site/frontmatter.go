@@ -0,0 +1,103 @@
+package site
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Field 是 front matter 中的一个字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields 是有序的 front matter 字段列表
+type Fields []Field
+
+// Add 添加字段，空字符串、空列表和 nil 会被忽略
+func (f *Fields) Add(key string, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case string:
+		if v == "" {
+			return
+		}
+	case []string:
+		if len(v) == 0 {
+			return
+		}
+	case time.Time:
+		if v.IsZero() {
+			return
+		}
+	}
+	*f = append(*f, Field{Key: key, Value: value})
+}
+
+// YAML 生成以 --- 分隔的 YAML front matter
+func YAML(fields Fields) string {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	for _, field := range fields {
+		fmt.Fprintf(&buf, "%s: %s\n", field.Key, formatValue(field.Value, "2006-01-02 15:04:05 -0700"))
+	}
+	buf.WriteString("---\n")
+	return buf.String()
+}
+
+// TOML 生成以 +++ 分隔的 TOML front matter，tables 按顺序输出为子表
+func TOML(fields Fields, tables ...Table) string {
+	var buf bytes.Buffer
+	buf.WriteString("+++\n")
+	for _, field := range fields {
+		fmt.Fprintf(&buf, "%s = %s\n", field.Key, formatValue(field.Value, time.RFC3339))
+	}
+	for _, table := range tables {
+		if len(table.Fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "\n[%s]\n", table.Name)
+		for _, field := range table.Fields {
+			fmt.Fprintf(&buf, "%s = %s\n", field.Key, formatValue(field.Value, time.RFC3339))
+		}
+	}
+	buf.WriteString("+++\n")
+	return buf.String()
+}
+
+// Table 是 TOML front matter 中的子表
+type Table struct {
+	Name   string
+	Fields Fields
+}
+
+// formatValue 输出 YAML 和 TOML 共同支持的字面量
+func formatValue(value interface{}, dateLayout string) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	case time.Time:
+		return v.Format(dateLayout)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quote 输出双引号字符串，JSON 的转义规则同时适用于 YAML 和 TOML
+func quote(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
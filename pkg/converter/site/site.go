@@ -0,0 +1,262 @@
+package site
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/media"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+	"github.com/mozillazg/go-pinyin"
+)
+
+// Article 是写入文件前的文章信息
+type Article struct {
+	Page     notionapi.Page
+	Metadata map[string]interface{}
+	// Slug 为由标题生成的文件名（不含扩展名）
+	Slug string
+	// Category 为映射后的分类目录
+	Category string
+	Date     time.Time
+	Content  string
+}
+
+// Dialect 描述一种静态站点生成器的约定
+type Dialect interface {
+	// Shortcodes 返回该生成器的嵌入语法
+	Shortcodes() notion.Shortcodes
+
+	// FrontMatter 生成包含分隔符的 front matter
+	FrontMatter(article *Article) string
+
+	// Path 返回文章相对输出目录的路径
+	Path(article *Article) string
+}
+
+// Preparer 由需要在写入文章前准备目录结构的 Dialect 实现，文件通过 w 写入
+type Preparer interface {
+	Prepare(w converter.Writer, outputPath string, article *Article) error
+}
+
+// OutputChecker 由对输出目录有要求的 Dialect 实现，设置输出目录时检查
+type OutputChecker interface {
+	CheckOutput(outputPath string) error
+}
+
+// Converter 是基于 Dialect 的通用 Markdown 转换器
+type Converter struct {
+	dialect        Dialect
+	outputPath     string
+	templatePath   string
+	writer         converter.Writer
+	blockProcessor converter.BlockProcessor
+	metaProcessor  converter.MetadataProcessor
+}
+
+func New(dialect Dialect, blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *Converter {
+	if handler, ok := blockProcessor.(*notion.BlockProcessor); ok {
+		handler.SetShortcodes(dialect.Shortcodes())
+	}
+	return &Converter{
+		dialect:        dialect,
+		writer:         converter.DiskWriter{},
+		blockProcessor: blockProcessor,
+		metaProcessor:  metaProcessor,
+	}
+}
+
+func (c *Converter) Convert(page notionapi.Page, blocks []notionapi.Block) error {
+	article, err := c.newArticle(page)
+	if err != nil {
+		return err
+	}
+	metadata := article.Metadata
+
+	if handler, ok := c.blockProcessor.(*notion.BlockProcessor); ok {
+		handler.SetPage(page)
+		if mediaHandler, ok := handler.GetMediaHandler().(media.ContextHandler); ok {
+			mediaHandler.SetContext(article.Category, article.Slug)
+		}
+	}
+
+	// 页面中包含目录块时开启 toc
+	if notion.HasBlockType(blocks, notionapi.BlockTypeTableOfContents) {
+		metadata["toc"] = true
+	}
+
+	// 处理内容
+	var content bytes.Buffer
+	if err := c.blockProcessor.ProcessBlocks(blocks, &content); err != nil {
+		return fmt.Errorf("处理块失败: %w", err)
+	}
+	article.Content = content.String()
+
+	// 报告未支持的块
+	if handler, ok := c.blockProcessor.(*notion.BlockProcessor); ok {
+		for _, skipped := range handler.Skipped() {
+			slog.Warn("跳过未支持的块", "page_id", string(page.ID), "title", metadata["title"], "block_type", skipped.Type, "block_id", skipped.ID, "url", skipped.URL)
+		}
+	}
+
+	if preparer, ok := c.dialect.(Preparer); ok {
+		if err := preparer.Prepare(c.writer, c.outputPath, article); err != nil {
+			return err
+		}
+	}
+
+	var output bytes.Buffer
+	if err := c.render(&output, article); err != nil {
+		return err
+	}
+
+	// 写入输出文件
+	return c.writer.WriteFile(filepath.Join(c.outputPath, c.dialect.Path(article)), output.Bytes())
+}
+
+func (c *Converter) Locate(page notionapi.Page) (string, string, error) {
+	article, err := c.newArticle(page)
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(c.outputPath, c.dialect.Path(article)), filepath.Join(article.Category, article.Slug), nil
+}
+
+// newArticle 处理页面的元数据，未配置分类映射时返回 ErrSkipPage
+func (c *Converter) newArticle(page notionapi.Page) (*Article, error) {
+	metadata, err := c.metaProcessor.ProcessMetadata(page)
+	if err != nil {
+		return nil, fmt.Errorf("处理元数据失败: %w", err)
+	}
+	if metadata == nil {
+		return nil, converter.ErrSkipPage
+	}
+
+	article := &Article{
+		Page:     page,
+		Metadata: metadata,
+		Slug:     Slugify(page, metadata),
+		Category: Category(metadata),
+		Date:     page.CreatedTime,
+	}
+	if date := article.Time("date"); !date.IsZero() {
+		article.Date = date
+	}
+	return article, nil
+}
+
+// render 输出文章，设置了模板时使用模板，否则使用生成器默认的 front matter
+func (c *Converter) render(f io.Writer, article *Article) error {
+	frontMatter := c.dialect.FrontMatter(article)
+	if c.templatePath == "" {
+		_, err := fmt.Fprintf(f, "%s\n%s", frontMatter, article.Content)
+		return err
+	}
+
+	tmpl, err := template.ParseFiles(c.templatePath)
+	if err != nil {
+		return fmt.Errorf("解析模板失败: %w", err)
+	}
+	data := map[string]interface{}{
+		"FrontMatter": frontMatter,
+		"Content":     article.Content,
+		"Metadata":    article.Metadata,
+		"Slug":        article.Slug,
+		"Category":    article.Category,
+	}
+	if err := tmpl.Execute(f, data); err != nil {
+		return fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return nil
+}
+
+func (c *Converter) SetOutput(path string) error {
+	if checker, ok := c.dialect.(OutputChecker); ok {
+		if err := checker.CheckOutput(path); err != nil {
+			return err
+		}
+	}
+	c.outputPath = path
+	return nil
+}
+
+// SetTemplate 设置可选的文章模板，为空时只输出 front matter 和正文
+func (c *Converter) SetTemplate(template string) error {
+	c.templatePath = template
+	return nil
+}
+
+func (c *Converter) SetWriter(w converter.Writer) {
+	c.writer = w
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Slugify 将标题转换为拼音文件名，标题为空时使用页面 ID
+func Slugify(page notionapi.Page, metadata map[string]interface{}) string {
+	title, ok := metadata["title"].(string)
+	if !ok || title == "" {
+		return string(page.ID)
+	}
+
+	// 转换为拼音
+	args := pinyin.NewArgs()
+	args.Separator = "-" // 分隔符
+	pys := pinyin.LazyPinyin(title, args)
+	slug := strings.Join(pys, "-")
+
+	// 清理文件名
+	slug = slugPattern.ReplaceAllString(slug, "-")
+	slug = strings.Trim(slug, "-")
+
+	// 如果清理后文件名为空，使用 ID
+	if slug == "" {
+		slug = string(page.ID)
+	}
+	return slug
+}
+
+// Category 返回文章的分类目录
+func Category(metadata map[string]interface{}) string {
+	// 获取第一个分类作为目录
+	if categoryDir, ok := metadata["category_dir"].(string); ok {
+		return categoryDir
+	}
+	if categories, ok := metadata["categories"].([]string); ok && len(categories) > 0 {
+		return categories[0]
+	}
+	return "uncategorized"
+}
+
+// String 返回字符串类型的元数据
+func (a *Article) String(key string) string {
+	s, _ := a.Metadata[key].(string)
+	return s
+}
+
+// Strings 返回字符串列表类型的元数据
+func (a *Article) Strings(key string) []string {
+	s, _ := a.Metadata[key].([]string)
+	return s
+}
+
+// Bool 返回布尔类型的元数据
+func (a *Article) Bool(key string) bool {
+	b, _ := a.Metadata[key].(bool)
+	return b
+}
+
+// Time 返回 RFC3339 格式的时间元数据
+func (a *Article) Time(key string) time.Time {
+	t, _ := time.Parse(time.RFC3339, a.String(key))
+	return t
+}
@@ -0,0 +1,108 @@
+package site
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestSlugify(t *testing.T) {
+	page := notionapi.Page{ID: "page-id"}
+	for _, tt := range []struct {
+		title string
+		want  string
+	}{
+		{"你好 世界", "ni-hao-shi-jie"},
+		{"标题：第一篇", "biao-ti-di-yi-pian"},
+		{"", "page-id"},
+		{"！？", "page-id"},
+	} {
+		if got := Slugify(page, map[string]interface{}{"title": tt.title}); got != tt.want {
+			t.Errorf("%q 的 slug 为 %q，期望 %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	for _, tt := range []struct {
+		metadata map[string]interface{}
+		want     string
+	}{
+		{map[string]interface{}{"category_dir": "tech", "categories": []string{"技术"}}, "tech"},
+		{map[string]interface{}{"categories": []string{"技术", "生活"}}, "技术"},
+		{map[string]interface{}{}, "uncategorized"},
+	} {
+		if got := Category(tt.metadata); got != tt.want {
+			t.Errorf("%v 的分类为 %q，期望 %q", tt.metadata, got, tt.want)
+		}
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	var f Fields
+	f.Add("title", `他说 "你好"`)
+	f.Add("empty", "")
+	f.Add("tags", []string{})
+	f.Add("lastmod", time.Time{})
+	f.Add("date", time.Date(2024, 5, 1, 8, 0, 0, 0, time.FixedZone("CST", 8*3600)))
+	f.Add("draft", false)
+
+	wantYAML := "---\ntitle: \"他说 \\\"你好\\\"\"\ndate: 2024-05-01 08:00:00 +0800\ndraft: false\n---\n"
+	if got := YAML(f); got != wantYAML {
+		t.Errorf("YAML 为:\n%s\n期望:\n%s", got, wantYAML)
+	}
+
+	var extra Fields
+	extra.Add("author", "张三")
+	wantTOML := "+++\ntitle = \"他说 \\\"你好\\\"\"\ndate = 2024-05-01T08:00:00+08:00\ndraft = false\n\n[extra]\nauthor = \"张三\"\n+++\n"
+	if got := TOML(f, Table{Name: "taxonomies"}, Table{Name: "extra", Fields: extra}); got != wantTOML {
+		t.Errorf("TOML 为:\n%s\n期望:\n%s", got, wantTOML)
+	}
+}
+
+type testDialect struct{}
+
+func (testDialect) Shortcodes() notion.Shortcodes { return notion.Shortcodes{} }
+func (testDialect) FrontMatter(a *Article) string { return "" }
+func (testDialect) Path(a *Article) string {
+	return filepath.Join(a.Category, a.Date.Format("2006")+"-"+a.Slug+".md")
+}
+func (testDialect) CheckOutput(outputPath string) error { return nil }
+
+type testMetadata map[string]interface{}
+
+func (m testMetadata) ProcessMetadata(page notionapi.Page) (map[string]interface{}, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return m, nil
+}
+
+func TestLocate(t *testing.T) {
+	page := notionapi.Page{ID: "page-id", CreatedTime: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c := New(testDialect{}, nil, testMetadata{"title": "你好", "category_dir": "tech", "date": "2024-05-01T08:00:00Z"})
+	if err := c.SetOutput("content"); err != nil {
+		t.Fatal(err)
+	}
+	file, mediaDir, err := c.Locate(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// date 属性优先于页面的创建时间
+	if want := filepath.Join("content", "tech", "2024-ni-hao.md"); file != want {
+		t.Errorf("文件为 %q，期望 %q", file, want)
+	}
+	if want := filepath.Join("tech", "ni-hao"); mediaDir != want {
+		t.Errorf("媒体目录为 %q，期望 %q", mediaDir, want)
+	}
+
+	c = New(testDialect{}, nil, testMetadata(nil))
+	if _, _, err := c.Locate(page); err != converter.ErrSkipPage {
+		t.Errorf("没有元数据的页面应返回 ErrSkipPage，得到 %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package zola
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"notion2md/pkg/converter"
+	"notion2md/pkg/converter/notion"
+	"notion2md/pkg/converter/site"
+)
+
+// Dialect 实现 Zola 的约定：TOML front matter，每个分类是一个带 _index.md 的 section
+type Dialect struct{}
+
+func New(blockProcessor converter.BlockProcessor, metaProcessor converter.MetadataProcessor) *site.Converter {
+	return site.New(Dialect{}, blockProcessor, metaProcessor)
+}
+
+func (Dialect) Shortcodes() notion.Shortcodes {
+	// Zola 0.11 起不再内置短代码，视频使用 iframe
+	return notion.Shortcodes{}
+}
+
+func (Dialect) FrontMatter(a *site.Article) string {
+	var f site.Fields
+	f.Add("title", a.String("title"))
+	f.Add("description", a.String("description"))
+	f.Add("date", a.Date)
+	f.Add("updated", a.Time("lastmod"))
+	f.Add("draft", a.Bool("draft"))
+	f.Add("slug", a.String("slug"))
+
+	var taxonomies site.Fields
+	taxonomies.Add("categories", a.Strings("categories"))
+	taxonomies.Add("tags", a.Strings("tags"))
+
+	var extra site.Fields
+	extra.Add("author", a.String("author"))
+	extra.Add("image", a.String("cover"))
+	extra.Add("meta_title", a.String("meta_title"))
+	if a.Bool("toc") {
+		extra.Add("toc", true)
+	}
+	if a.Bool("comments") {
+		extra.Add("comments", true)
+	}
+
+	return site.TOML(f,
+		site.Table{Name: "taxonomies", Fields: taxonomies},
+		site.Table{Name: "extra", Fields: extra},
+	)
+}
+
+func (Dialect) Path(a *site.Article) string {
+	return filepath.Join(a.Category, a.Slug+".md")
+}
+
+// Prepare 为分类目录创建 Zola 要求的 _index.md
+func (Dialect) Prepare(w converter.Writer, outputPath string, a *site.Article) error {
+	index := filepath.Join(outputPath, a.Category, "_index.md")
+	if _, err := os.Stat(index); err == nil {
+		return nil
+	}
+
+	var f site.Fields
+	f.Add("title", a.Category)
+	f.Add("sort_by", "date")
+	if err := w.WriteFile(index, []byte(site.TOML(f))); err != nil {
+		return fmt.Errorf("创建 section 失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package zola
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"notion2md/pkg/converter/site"
+
+	"github.com/jomei/notionapi"
+)
+
+func TestDialect(t *testing.T) {
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name        string
+		metadata    map[string]interface{}
+		path        string
+		frontMatter string
+	}{
+		{
+			name: "文章",
+			metadata: map[string]interface{}{
+				"title":        "你好",
+				"lastmod":      "2024-05-02T09:30:00Z",
+				"category_dir": "tech",
+				"categories":   []string{"技术"},
+				"tags":         []string{"Go"},
+				"author":       "张三",
+				"toc":          true,
+				"slug":         "hello",
+			},
+			path: filepath.Join("tech", "ni-hao.md"),
+			frontMatter: `+++
+title = "你好"
+date = 2024-05-01T08:00:00Z
+updated = 2024-05-02T09:30:00Z
+draft = false
+slug = "hello"
+
+[taxonomies]
+categories = ["技术"]
+tags = ["Go"]
+
+[extra]
+author = "张三"
+toc = true
++++
+`,
+		},
+		{
+			// 没有分类时写入 uncategorized，没有 extra 字段时不输出子表
+			name:     "草稿",
+			metadata: map[string]interface{}{"title": "草稿", "draft": true},
+			path:     filepath.Join("uncategorized", "cao-gao.md"),
+			frontMatter: `+++
+title = "草稿"
+date = 2024-05-01T08:00:00Z
+draft = true
++++
+`,
+		},
+	} {
+		a := &site.Article{Metadata: tt.metadata, Slug: site.Slugify(notionapi.Page{}, tt.metadata), Category: site.Category(tt.metadata), Date: date}
+		if got := (Dialect{}).Path(a); got != tt.path {
+			t.Errorf("%s 的路径为 %q，期望 %q", tt.name, got, tt.path)
+		}
+		if got := (Dialect{}).FrontMatter(a); got != tt.frontMatter {
+			t.Errorf("%s 的 front matter 为:\n%s\n期望:\n%s", tt.name, got, tt.frontMatter)
+		}
+	}
+}
+
+type memoryWriter map[string]string
+
+func (w memoryWriter) WriteFile(path string, data []byte) error {
+	w[path] = string(data)
+	return nil
+}
+
+func TestPrepare(t *testing.T) {
+	dir := t.TempDir()
+	w := memoryWriter{}
+	a := &site.Article{Category: "tech"}
+	if err := (Dialect{}).Prepare(w, dir, a); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, "tech", "_index.md")
+	want := "+++\ntitle = \"tech\"\nsort_by = \"date\"\n+++\n"
+	if w[index] != want {
+		t.Errorf("_index.md 为 %q，期望 %q", w[index], want)
+	}
+
+	// 已存在的 _index.md 不会被覆盖
+	if err := os.MkdirAll(filepath.Dir(index), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(index, []byte("自定义"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w = memoryWriter{}
+	if err := (Dialect{}).Prepare(w, dir, a); err != nil {
+		t.Fatal(err)
+	}
+	if len(w) != 0 {
+		t.Errorf("不应重写已存在的 _index.md: %v", w)
+	}
+}
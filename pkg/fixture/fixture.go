@@ -0,0 +1,105 @@
+// Package fixture 录制和回放 Notion API 的响应，并提供进程内的假 Notion 服务，
+// 用于在没有网络的环境下确定性地测试同步流程。
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Fixture 是录制的一次请求和响应，请求头（包括 Authorization）不会被保存
+type Fixture struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	RequestBody json.RawMessage `json:"requestBody,omitempty"`
+	Status      int             `json:"status"`
+	Header      http.Header     `json:"header,omitempty"`
+	Body        json.RawMessage `json:"body"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+var datePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}([T ][0-9:.]+(Z|[+-]\d{2}:?\d{2})?)?$`)
+
+// Filename 返回请求对应的记录文件名，由方法、路径和请求内容的摘要组成
+//
+// 请求体不同的同一路径（例如分页查询的 start_cursor）保存为不同的文件。请求体中的日期
+// （例如按当天日期过滤的查询条件）不参与摘要，录制的查询在其他日期也能回放。
+func Filename(method, url string, body []byte) string {
+	sum := sha256.Sum256([]byte(method + " " + url + "\n" + string(normalizeBody(body))))
+	name := unsafeChars.ReplaceAllString(strings.TrimPrefix(stripHost(url), "/"), "_")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	return fmt.Sprintf("%s_%s_%s.json", method, strings.Trim(name, "_"), hex.EncodeToString(sum[:4]))
+}
+
+// normalizeBody 把 JSON 请求体中的日期和时间替换为占位符，不是 JSON 时原样返回
+func normalizeBody(body []byte) []byte {
+	var v interface{}
+	if len(body) == 0 || json.Unmarshal(body, &v) != nil {
+		return body
+	}
+	normalized, err := json.Marshal(replaceDates(v))
+	if err != nil {
+		return body
+	}
+	return normalized
+}
+
+func replaceDates(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			value[key] = replaceDates(child)
+		}
+	case []interface{}:
+		for i, child := range value {
+			value[i] = replaceDates(child)
+		}
+	case string:
+		if datePattern.MatchString(value) {
+			return "<date>"
+		}
+	}
+	return v
+}
+
+// stripHost 去掉 URL 中的协议和主机，录制和回放时的主机可能不同
+func stripHost(url string) string {
+	if i := strings.Index(url, "://"); i >= 0 {
+		rest := url[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	return url
+}
+
+// readBody 读取并还原请求体，返回的内容用于计算记录文件名
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// path 返回请求在 dir 中的记录文件路径
+func path(dir string, req *http.Request, body []byte) string {
+	return filepath.Join(dir, Filename(req.Method, req.URL.String(), body))
+}
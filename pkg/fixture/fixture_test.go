@@ -0,0 +1,205 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+)
+
+const testPage = `{
+	"id": "11111111-2222-3333-4444-555555555555",
+	"created_time": "2024-01-01T00:00:00Z",
+	"last_edited_time": "2024-01-02T00:00:00Z",
+	"properties": {
+		"Status": {"id": "s", "type": "status", "status": {"name": "Ready"}}
+	}
+}`
+
+func newClient(transport http.RoundTripper) *notionapi.Client {
+	return notionapi.NewClient("secret-token", notionapi.WithHTTPClient(&http.Client{Transport: transport}))
+}
+
+func TestServerPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.PageSize = 1
+	server.AddPage("db", testPage)
+	server.AddPage("db", strings.Replace(testPage, "5555", "6666", 1))
+
+	client := newClient(server.Transport())
+	query := &notionapi.DatabaseQueryRequest{PageSize: 100}
+	resp, err := client.Database.Query(context.Background(), "db", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || !resp.HasMore || resp.NextCursor == "" {
+		t.Fatalf("第一页应只有一条且有下一页: %+v", resp)
+	}
+
+	query.StartCursor = resp.NextCursor
+	resp, err = client.Database.Query(context.Background(), "db", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.HasMore {
+		t.Fatalf("第二页应只有一条且没有下一页: %+v", resp)
+	}
+}
+
+func TestServerUpdatePage(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddPage("db", testPage)
+
+	client := newClient(server.Transport())
+	_, err := client.Page.Update(context.Background(), "11111111-2222-3333-4444-555555555555", &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{
+			"Status": notionapi.StatusProperty{Status: notionapi.Status{Name: "Published"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := client.Page.Get(context.Background(), "11111111222233334444555555555555")
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, ok := page.Properties["Status"].(*notionapi.StatusProperty)
+	if !ok || status.Status.Name != "Published" {
+		t.Errorf("状态未更新: %#v", page.Properties["Status"])
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddPage("db", testPage)
+	server.AddBlocks("11111111-2222-3333-4444-555555555555", `{"object": "block", "id": "b", "type": "divider", "divider": {}}`)
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(server.Transport(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := newClient(recorder)
+	if _, err := recorded.Database.Query(context.Background(), "db", &notionapi.DatabaseQueryRequest{PageSize: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := recorded.Block.GetChildren(context.Background(), "11111111-2222-3333-4444-555555555555", &notionapi.Pagination{PageSize: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 记录中不能包含密钥
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("应录制 2 个响应，实际 %d", len(files))
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "secret-token") {
+			t.Errorf("%s 包含密钥", file)
+		}
+	}
+
+	// 关闭服务后仍能回放
+	server.Close()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed := newClient(replayer)
+	resp, err := replayed.Database.Query(context.Background(), "db", &notionapi.DatabaseQueryRequest{PageSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != "11111111-2222-3333-4444-555555555555" {
+		t.Errorf("回放结果不一致: %+v", resp.Results)
+	}
+	children, err := replayed.Block.GetChildren(context.Background(), "11111111-2222-3333-4444-555555555555", &notionapi.Pagination{PageSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children.Results) != 1 || children.Results[0].GetType() != notionapi.BlockTypeDivider {
+		t.Errorf("回放的子块不一致: %+v", children.Results)
+	}
+
+	// 没有录制的请求返回错误
+	if _, err := replayed.Page.Get(context.Background(), "11111111-2222-3333-4444-555555555555"); err == nil || !strings.Contains(err.Error(), "没有") {
+		t.Errorf("未录制的请求应返回错误: %v", err)
+	}
+}
+
+func TestFilenameIgnoresDates(t *testing.T) {
+	query := func(date, cursor string) []byte {
+		return []byte(`{"filter": {"and": [{"property": "Publish Date", "date": {"on_or_before": "` + date + `"}}]}, "start_cursor": "` + cursor + `"}`)
+	}
+	name := Filename("POST", "/v1/databases/db/query", query("2024-05-01", "a"))
+	for _, date := range []string{"2024-05-02", "2024-05-02T08:00:00Z", "2024-05-02T08:00:00.000+08:00"} {
+		if got := Filename("POST", "/v1/databases/db/query", query(date, "a")); got != name {
+			t.Errorf("日期为 %s 的查询保存为 %s，期望 %s", date, got, name)
+		}
+	}
+	if got := Filename("POST", "/v1/databases/db/query", query("2024-05-01", "b")); got == name {
+		t.Error("start_cursor 不同的查询应保存为不同的文件")
+	}
+}
+
+func TestRecorderKeepsFirstSuccess(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddPage("db", testPage)
+	const id = "11111111-2222-3333-4444-555555555555"
+
+	dir := t.TempDir()
+	recorder, err := NewRecorder(server.Transport(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := newClient(recorder)
+
+	// 失败的响应被之后的成功响应替换
+	server.FailNext(http.MethodGet, http.StatusInternalServerError)
+	if _, err := client.Page.Get(context.Background(), id); err == nil {
+		t.Fatal("第一次请求应失败")
+	}
+	if _, err := client.Page.Get(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	// 之后的成功响应不会覆盖第一个成功响应
+	_, err = client.Page.Update(context.Background(), id, &notionapi.PageUpdateRequest{
+		Properties: notionapi.Properties{
+			"Status": notionapi.StatusProperty{Status: notionapi.Status{Name: "Published"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Page.Get(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := newClient(replayer).Page.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, ok := page.Properties["Status"].(*notionapi.StatusProperty); !ok || status.Status.Name != "Ready" {
+		t.Errorf("回放的应是第一个成功响应: %#v", page.Properties["Status"])
+	}
+}
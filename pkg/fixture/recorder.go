@@ -0,0 +1,95 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// Recorder 转发请求并把每个响应保存到 dir 中，供 Replayer 回放
+type Recorder struct {
+	base http.RoundTripper
+	dir  string
+}
+
+// NewRecorder 创建录制传输层，base 为 nil 时使用 http.DefaultTransport
+func NewRecorder(base http.RoundTripper, dir string) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %w", err)
+	}
+	return &Recorder{base: base, dir: dir}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求失败: %w", err)
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	// 只保存同一请求的第一个成功响应，重试前的失败响应会被之后的成功响应替换
+	file := path(r.dir, req, body)
+	if recorded(file) {
+		return resp, nil
+	}
+	fixture := Fixture{
+		Method: req.Method,
+		URL:    stripHost(req.URL.String()),
+		Status: resp.StatusCode,
+		Body:   rawJSON(data),
+	}
+	if len(body) > 0 {
+		fixture.RequestBody = rawJSON(body)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		fixture.Header = http.Header{"Retry-After": {retryAfter}}
+	}
+
+	out, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(file, append(out, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("保存记录失败: %w", err)
+	}
+	return resp, nil
+}
+
+// recorded 判断 file 中是否已保存成功的响应
+func recorded(file string) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return false
+	}
+	return fixture.Status >= 200 && fixture.Status < 300
+}
+
+// rawJSON 原样保存 JSON 内容，不是 JSON 时保存为字符串
+func rawJSON(data []byte) json.RawMessage {
+	if json.Valid(data) {
+		return data
+	}
+	quoted, _ := json.Marshal(string(data))
+	return quoted
+}
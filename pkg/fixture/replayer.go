@@ -0,0 +1,66 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// Replayer 从 Recorder 保存的记录中返回响应，不访问网络
+type Replayer struct {
+	dir string
+}
+
+func NewReplayer(dir string) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取回放目录失败: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("回放路径不是目录: %s", dir)
+	}
+	return &Replayer{dir: dir}, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求失败: %w", err)
+	}
+
+	file := path(r.dir, req, body)
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("没有 %s %s 的记录: %s", req.Method, req.URL.RequestURI(), file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取记录失败: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("解析记录 %s 失败: %w", file, err)
+	}
+
+	header := fixture.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(fixture.Body)),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       req,
+	}, nil
+}
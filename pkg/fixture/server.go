@@ -0,0 +1,313 @@
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server 是进程内的假 Notion API，支持读取集成用户、数据库读取和查询、页面读取和更新、子块读取
+//
+// 数据库查询忽略过滤条件和排序，按添加顺序返回数据库中的所有页面。
+type Server struct {
+	// PageSize 是每页返回的最大条数，小于请求的 page_size 时生效，用于测试分页
+	PageSize int
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	databases map[string][]string
+	schemas   map[string]map[string]interface{}
+	pages     map[string]map[string]interface{}
+	children  map[string][]json.RawMessage
+	failures  []failure
+	requests  []Request
+}
+
+type failure struct {
+	method string
+	status int
+}
+
+// Request 是假服务收到的请求
+type Request struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// NewServer 启动假 Notion 服务，使用完毕后需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		PageSize:  100,
+		databases: make(map[string][]string),
+		schemas:   make(map[string]map[string]interface{}),
+		pages:     make(map[string]map[string]interface{}),
+		children:  make(map[string][]json.RawMessage),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Transport 返回把 api.notion.com 的请求转发到假服务的传输层
+func (s *Server) Transport() http.RoundTripper {
+	target, _ := url.Parse(s.server.URL)
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.Host = target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// AddPage 把页面 JSON 加入数据库，页面的 parent 会被设置为该数据库
+func (s *Server) AddPage(databaseID, page string) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(page), &data); err != nil {
+		panic(fmt.Sprintf("页面 JSON 无效: %v", err))
+	}
+	id, _ := data["id"].(string)
+	data["object"] = "page"
+	data["parent"] = map[string]interface{}{"type": "database_id", "database_id": databaseID}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[normalize(id)] = data
+	s.databases[normalize(databaseID)] = append(s.databases[normalize(databaseID)], normalize(id))
+}
+
+// SetDatabase 设置数据库本身的 JSON，用于读取数据库的属性结构
+func (s *Server) SetDatabase(id, database string) {
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(database), &data); err != nil {
+		panic(fmt.Sprintf("数据库 JSON 无效: %v", err))
+	}
+	data["object"] = "database"
+	data["id"] = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schemas[normalize(id)] = data
+}
+
+// AddBlocks 为页面或块添加子块，每个块是 Notion API 格式的 JSON
+func (s *Server) AddBlocks(parentID string, blocks ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, block := range blocks {
+		s.children[normalize(parentID)] = append(s.children[normalize(parentID)], json.RawMessage(block))
+	}
+}
+
+// FailNext 让接下来方法为 method 的请求依次返回给定的状态码，method 为空时匹配所有请求
+//
+// 429 响应带有 Retry-After: 0。
+func (s *Server) FailNext(method string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, status := range statuses {
+		s.failures = append(s.failures, failure{method: method, status: status})
+	}
+}
+
+// Page 返回页面当前的 JSON，包含通过 API 更新的属性
+func (s *Server) Page(id string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pages[normalize(id)]
+}
+
+// Requests 返回收到的所有请求
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = readBody(r)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Body: string(body)})
+
+	for i, f := range s.failures {
+		if f.method != "" && f.method != r.Method {
+			continue
+		}
+		s.failures = append(s.failures[:i], s.failures[i+1:]...)
+		if f.status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "0")
+		}
+		writeError(w, f.status, "injected_failure", "注入的失败响应")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1"), "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "users" && parts[1] == "me":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"object": "user", "id": "bot", "type": "bot", "name": "fixture", "bot": map[string]interface{}{}})
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "databases":
+		s.getDatabase(w, parts[1])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "databases" && parts[2] == "query":
+		s.queryDatabase(w, parts[1], body)
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "pages":
+		s.getPage(w, parts[1])
+	case r.Method == http.MethodPatch && len(parts) == 2 && parts[0] == "pages":
+		s.updatePage(w, parts[1], body)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "blocks" && parts[2] == "children":
+		s.getChildren(w, parts[1], r.URL.Query())
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_url", "不支持的请求: "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (s *Server) getDatabase(w http.ResponseWriter, id string) {
+	database, ok := s.schemas[normalize(id)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "object_not_found", "数据库不存在: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, database)
+}
+
+func (s *Server) queryDatabase(w http.ResponseWriter, id string, body []byte) {
+	ids, ok := s.databases[normalize(id)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "object_not_found", "数据库不存在: "+id)
+		return
+	}
+
+	var query struct {
+		StartCursor string `json:"start_cursor"`
+		PageSize    int    `json:"page_size"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &query); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+			return
+		}
+	}
+
+	results := make([]interface{}, 0, len(ids))
+	for _, pageID := range ids {
+		results = append(results, s.pages[pageID])
+	}
+	s.writeList(w, results, query.StartCursor, query.PageSize)
+}
+
+func (s *Server) getPage(w http.ResponseWriter, id string) {
+	page, ok := s.pages[normalize(id)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "object_not_found", "页面不存在: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) updatePage(w http.ResponseWriter, id string, body []byte) {
+	page, ok := s.pages[normalize(id)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "object_not_found", "页面不存在: "+id)
+		return
+	}
+
+	var update struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}
+	if err := json.Unmarshal(body, &update); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return
+	}
+
+	// 按属性名替换属性值，保留属性原有的类型和 ID
+	properties, _ := page["properties"].(map[string]interface{})
+	if properties == nil {
+		properties = make(map[string]interface{})
+		page["properties"] = properties
+	}
+	for name, value := range update.Properties {
+		old, _ := properties[name].(map[string]interface{})
+		for _, key := range []string{"id", "type"} {
+			if v, _ := value[key].(string); v == "" && old != nil {
+				value[key] = old[key]
+			}
+		}
+		properties[name] = value
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) getChildren(w http.ResponseWriter, id string, query url.Values) {
+	pageSize, _ := strconv.Atoi(query.Get("page_size"))
+	results := make([]interface{}, 0, len(s.children[normalize(id)]))
+	for _, block := range s.children[normalize(id)] {
+		results = append(results, block)
+	}
+	s.writeList(w, results, query.Get("start_cursor"), pageSize)
+}
+
+// writeList 返回分页列表，游标是下一页第一条的序号
+func (s *Server) writeList(w http.ResponseWriter, results []interface{}, cursor string, pageSize int) {
+	start, _ := strconv.Atoi(cursor)
+	if start > len(results) {
+		start = len(results)
+	}
+	if pageSize <= 0 || pageSize > s.PageSize {
+		pageSize = s.PageSize
+	}
+	end := start + pageSize
+	if end > len(results) {
+		end = len(results)
+	}
+
+	list := map[string]interface{}{
+		"object":   "list",
+		"results":  results[start:end],
+		"has_more": end < len(results),
+	}
+	if end < len(results) {
+		list["next_cursor"] = strconv.Itoa(end)
+	} else {
+		list["next_cursor"] = nil
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"object":  "error",
+		"status":  status,
+		"code":    code,
+		"message": message,
+	})
+}
+
+func normalize(id string) string {
+	return strings.ReplaceAll(id, "-", "")
+}